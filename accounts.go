@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// accountsFileName 디렉토리 형식에서 계정과 권한을 기록하는 파일
+const accountsFileName = "users.sql"
+
+// systemAccounts 서버가 내부적으로 쓰는 계정 (복원 서버에도 이미 있으므로 백업하지 않음)
+var systemAccounts = map[string]bool{
+	"mysql.sys":        true,
+	"mysql.session":    true,
+	"mysql.infoschema": true,
+	"mariadb.sys":      true,
+}
+
+// account 계정 또는 롤 하나
+type account struct {
+	User string
+	Host string
+	Role bool // 다른 계정에 부여되는 롤 (계정보다 먼저 만들어야 함)
+
+	// NoLogin 잠겨 있고 비밀번호가 없는 계정 (MySQL 8의 CREATE ROLE로 만든 그대로라 CREATE ROLE로 복원)
+	NoLogin bool
+}
+
+// Name 'user'@'host' 형식의 계정 이름 (MariaDB 롤은 호스트 없이 'role')
+func (a account) Name(mariadb bool) string {
+	if mariadb && a.Role {
+		return "'" + escapeSQLString(a.User) + "'"
+	}
+	return "'" + escapeSQLString(a.User) + "'@'" + escapeSQLString(a.Host) + "'"
+}
+
+// accountPattern BACKUP_USERS_PATTERN의 user@host 패턴 하나 (LIKE처럼 %와 _ 사용)
+type accountPattern struct {
+	user *regexp.Regexp
+	host *regexp.Regexp
+}
+
+// parseAccountPatterns 쉼표로 구분된 user@host 패턴 목록을 읽습니다 (@가 없으면 모든 호스트)
+func parseAccountPatterns(s string) []accountPattern {
+	var patterns []accountPattern
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		user, host := item, "%"
+		if i := strings.LastIndex(item, "@"); i >= 0 {
+			user, host = item[:i], item[i+1:]
+		}
+		patterns = append(patterns, accountPattern{user: likePattern(user), host: likePattern(host)})
+	}
+	return patterns
+}
+
+// likePattern LIKE 패턴(%: 임의 문자열, _: 임의 문자 하나, \: 다음 문자를 그대로)을 정규식으로 변환합니다
+func likePattern(s string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escaped {
+		// 끝에 남은 \는 문자 그대로
+		b.WriteString(`\\`)
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// matchAccount 패턴이 없으면 모든 계정, 있으면 하나라도 맞는 계정만 백업합니다
+func matchAccount(patterns []accountPattern, a account) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if p.user.MatchString(a.User) && p.host.MatchString(a.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpAccounts 계정, 롤, 권한을 복원할 수 있는 SQL로 만듭니다 (BACKUP_USERS=on)
+// 롤을 먼저 만들고 모든 계정을 만든 뒤 권한을 부여하므로, 롤 부여와 DEFAULT ROLE이 순서와 관계없이 복원됩니다
+// 이미 있는 계정은 건너뛰도록 CREATE USER IF NOT EXISTS를 쓰며, MySQL 8과 MariaDB 문법을 모두 지원합니다
+func (mb *MySQLBackup) dumpAccounts(ctx context.Context) (string, int, error) {
+	// 세션 변수를 쓰므로 연결 하나로 조회
+	conn, err := mb.db.Conn(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	defer conn.Close()
+
+	var version string
+	if err := conn.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return "", 0, fmt.Errorf("서버 버전 조회 실패: %v", err)
+	}
+	mariadb := strings.Contains(strings.ToLower(version), "mariadb")
+
+	accounts, err := listAccounts(ctx, conn, mariadb)
+	if err != nil {
+		return "", 0, fmt.Errorf("계정 목록 조회 실패: %v", err)
+	}
+	patterns := parseAccountPatterns(mb.config.UserPatterns)
+	selected := accounts[:0]
+	for _, a := range accounts {
+		if !systemAccounts[a.User] && matchAccount(patterns, a) {
+			selected = append(selected, a)
+		}
+	}
+	accounts = selected
+
+	if !mariadb {
+		// caching_sha2_password 해시에는 출력할 수 없는 바이트가 있으므로 16진수로 받음 (8.0.17+, 그 전 버전은 무시)
+		if _, err := conn.ExecContext(ctx, "SET SESSION print_identified_with_as_hex = ON"); err != nil {
+			mb.logger.Debug("print_identified_with_as_hex를 설정하지 못했습니다", "error", err)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n-- 계정과 권한: %d개 (복원하려면 CREATE USER와 GRANT OPTION 권한이 필요합니다)\n", len(accounts)))
+	for _, a := range accounts {
+		statement, err := createAccountSQL(ctx, conn, a, mariadb)
+		if err != nil {
+			return "", 0, fmt.Errorf("계정 %s 생성 문 조회 실패: %v", a.Name(mariadb), err)
+		}
+		b.WriteString(statement + ";\n")
+	}
+	for _, a := range accounts {
+		grants, err := accountGrants(ctx, conn, a, mariadb)
+		if err != nil {
+			return "", 0, fmt.Errorf("계정 %s 권한 조회 실패: %v", a.Name(mariadb), err)
+		}
+		for _, grant := range grants {
+			b.WriteString(grant + ";\n")
+		}
+	}
+
+	mb.logger.Info("계정과 권한을 백업합니다", "accounts", len(accounts), "mariadb", mariadb)
+	return b.String(), len(accounts), nil
+}
+
+// listAccounts mysql.user의 계정 목록 (롤이 먼저 오도록 정렬)
+// MariaDB는 is_role 컬럼을 쓰고, MySQL 8은 markMySQLRoles로 롤을 찾습니다
+func listAccounts(ctx context.Context, conn *sql.Conn, mariadb bool) ([]account, error) {
+	query := "SELECT User, Host, IF(account_locked = 'Y' AND authentication_string = '', 'Y', 'N') FROM mysql.user"
+	if mariadb {
+		query = "SELECT User, Host, is_role FROM mysql.user"
+	}
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil && !mariadb {
+		// 5.7.6 미만에는 account_locked 컬럼이 없음
+		rows, err = conn.QueryContext(ctx, "SELECT User, Host, 'N' FROM mysql.user")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []account
+	for rows.Next() {
+		var a account
+		var flag string
+		if err := rows.Scan(&a.User, &a.Host, &flag); err != nil {
+			return nil, err
+		}
+		if mariadb {
+			a.Role = flag == "Y"
+		} else {
+			a.NoLogin = flag == "Y"
+		}
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !mariadb {
+		// MySQL 5.7에는 롤이 없어 테이블이 없으면 모두 일반 계정
+		roles := make(map[[2]string]bool)
+		supported := false
+		for _, query := range []string{
+			"SELECT DISTINCT FROM_USER, FROM_HOST FROM mysql.role_edges",
+			"SELECT DISTINCT DEFAULT_ROLE_USER, DEFAULT_ROLE_HOST FROM mysql.default_roles",
+		} {
+			rows, err := conn.QueryContext(ctx, query)
+			if err != nil {
+				continue
+			}
+			supported = true
+			for rows.Next() {
+				var user, host string
+				if err := rows.Scan(&user, &host); err == nil {
+					roles[[2]string{user, host}] = true
+				}
+			}
+			rows.Close()
+		}
+		markMySQLRoles(accounts, roles, supported)
+	}
+
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if accounts[i].Role != accounts[j].Role {
+			return accounts[i].Role
+		}
+		if accounts[i].User != accounts[j].User {
+			return accounts[i].User < accounts[j].User
+		}
+		return accounts[i].Host < accounts[j].Host
+	})
+	return accounts, nil
+}
+
+// markMySQLRoles MySQL 계정 중 롤을 표시합니다
+// 다른 계정에 부여되었거나 기본 롤로 지정된 계정, 그리고 아직 아무에게도 부여되지 않았어도 잠겨 있고 비밀번호가 없는 계정(CREATE ROLE의 결과)을 롤로 봅니다
+// 롤을 지원하지 않는 서버(5.7)는 CREATE ROLE을 실행할 수 없으므로 모두 일반 계정으로 둡니다
+func markMySQLRoles(accounts []account, roles map[[2]string]bool, supported bool) {
+	for i := range accounts {
+		if !supported {
+			accounts[i].Role, accounts[i].NoLogin = false, false
+			continue
+		}
+		accounts[i].Role = accounts[i].NoLogin || roles[[2]string{accounts[i].User, accounts[i].Host}]
+	}
+}
+
+// createAccountSQL SHOW CREATE USER 결과를 이미 있는 계정은 건너뛰는 CREATE USER IF NOT EXISTS로 바꿉니다
+// MariaDB 롤은 SHOW CREATE USER로 조회할 수 없고, 로그인할 수 없는 MySQL 롤은 CREATE ROLE과 같으므로 CREATE ROLE을 씁니다
+// 비밀번호가 있거나 잠기지 않은 MySQL 롤은 속성을 잃지 않도록 SHOW CREATE USER 결과를 그대로 씁니다
+func createAccountSQL(ctx context.Context, conn *sql.Conn, a account, mariadb bool) (string, error) {
+	if a.Role && (mariadb || a.NoLogin) {
+		return "CREATE ROLE IF NOT EXISTS " + a.Name(mariadb), nil
+	}
+	var statement string
+	if err := conn.QueryRowContext(ctx, "SHOW CREATE USER "+a.Name(mariadb)).Scan(&statement); err != nil {
+		return "", err
+	}
+	if rest, ok := strings.CutPrefix(statement, "CREATE USER "); ok && !strings.HasPrefix(rest, "IF NOT EXISTS ") {
+		statement = "CREATE USER IF NOT EXISTS " + rest
+	}
+	return statement, nil
+}
+
+// accountGrants SHOW GRANTS 결과 (롤 부여와 MariaDB의 SET DEFAULT ROLE 포함)
+func accountGrants(ctx context.Context, conn *sql.Conn, a account, mariadb bool) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, "SHOW GRANTS FOR "+a.Name(mariadb))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLikePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"app", "app", true},
+		{"app", "app2", false},
+		{"app", "APP", false},
+		{"%", "", true},
+		{"%", "anything", true},
+		{"app_%", "app_read", true},
+		{"app_%", "app", false},
+		{"app_%", "appx", true}, // _는 임의 문자 하나
+		{"a_c", "abc", true},
+		{"a_c", "ac", false},
+		{"a_c", "abbc", false},
+		{"a_c", "a한c", true}, // 바이트가 아닌 문자 단위
+		{`app\_%`, "app_read", true},
+		{`app\_%`, "appxread", false},
+		{`100\%`, "100%", true},
+		{`100\%`, "1000", false},
+		{`back\\slash`, `back\slash`, true},
+		{`trailing\`, `trailing\`, true},
+		{"10.%", "10.0.0.1", true},
+		{"10.%", "1000.0.1", false}, // .은 정규식이 아닌 문자 그대로
+		{"a+b", "a+b", true},
+		{"a+b", "aab", false},
+		{"(x)|y", "y", false},
+	}
+	for _, tt := range tests {
+		if got := likePattern(tt.pattern).MatchString(tt.value); got != tt.want {
+			t.Errorf("likePattern(%q) matches %q = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAccount(t *testing.T) {
+	tests := []struct {
+		patterns string
+		user     string
+		host     string
+		want     bool
+	}{
+		{"", "anyone", "anywhere", true},
+		{" , ", "anyone", "anywhere", true},
+		{"app", "app", "10.0.0.1", true}, // @가 없으면 모든 호스트
+		{"app@localhost", "app", "localhost", true},
+		{"app@localhost", "app", "%", false},
+		{"app@%", "app", "%", true},
+		{"app@10.%", "app", "10.1.2.3", true},
+		{"app@10.%", "app", "192.168.0.1", false},
+		{"app_%@%, report@10.%", "report", "10.0.0.5", true},
+		{"app_%@%, report@10.%", "report", "localhost", false},
+		{"app_%@%, report@10.%", "app_api", "localhost", true},
+		{"user@corp@%", "user@corp", "db1", true}, // 마지막 @로 나눔
+		{`app\_%@%`, "appxapi", "%", false},
+	}
+	for _, tt := range tests {
+		got := matchAccount(parseAccountPatterns(tt.patterns), account{User: tt.user, Host: tt.host})
+		if got != tt.want {
+			t.Errorf("matchAccount(%q, %s@%s) = %v, want %v", tt.patterns, tt.user, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestMarkMySQLRoles(t *testing.T) {
+	accounts := []account{
+		{User: "app", Host: "%"},
+		{User: "granted_role", Host: "%"},
+		{User: "default_only", Host: "%"},
+		{User: "new_role", Host: "%", NoLogin: true}, // 아직 아무에게도 부여되지 않은 롤
+		{User: "granted_role", Host: "localhost"},
+	}
+	roles := map[[2]string]bool{
+		{"granted_role", "%"}: true,
+		{"default_only", "%"}: true,
+	}
+	markMySQLRoles(accounts, roles, true)
+	want := []bool{false, true, true, true, false}
+	for i, a := range accounts {
+		if a.Role != want[i] {
+			t.Errorf("%s@%s: Role = %v, want %v", a.User, a.Host, a.Role, want[i])
+		}
+	}
+
+	// 롤이 없는 서버(5.7)는 CREATE ROLE을 쓸 수 없으므로 모두 일반 계정
+	markMySQLRoles(accounts, nil, false)
+	for _, a := range accounts {
+		if a.Role || a.NoLogin {
+			t.Errorf("%s@%s without role support: Role %v, NoLogin %v", a.User, a.Host, a.Role, a.NoLogin)
+		}
+	}
+}
+
+func TestAccountName(t *testing.T) {
+	tests := []struct {
+		account account
+		mariadb bool
+		want    string
+	}{
+		{account{User: "app", Host: "%"}, false, `'app'@'%'`},
+		{account{User: "o'neil", Host: "10.%"}, false, `'o\'neil'@'10.%'`},
+		{account{User: "reader", Host: "", Role: true}, true, `'reader'`},
+		{account{User: "reader", Host: "%", Role: true}, false, `'reader'@'%'`},
+	}
+	for _, tt := range tests {
+		if got := tt.account.Name(tt.mariadb); got != tt.want {
+			t.Errorf("Name(%+v, mariadb=%v) = %s, want %s", tt.account, tt.mariadb, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSQLDumpPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	// 이전 실행이 넓은 권한으로 남긴 임시 파일도 좁혀야 함
+	if err := os.WriteFile(path+".tmp", []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mb := &MySQLBackup{config: &BackupConfig{Users: "on"}}
+	if err := mb.writeSQLDump(path, "-- header\n", "-- footer\n", nil); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("BACKUP_USERS=on: mode %o, want 600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "-- header\n-- footer\n" {
+		t.Errorf("dump = %q", data)
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 체크포인트의 테이블 상태
+const (
+	TableStatusInProgress = "in_progress"
+	TableStatusCompleted  = "completed"
+)
+
+// CursorValue 커서 컬럼의 마지막 값을 타입 정보와 함께 저장합니다
+// 재개할 때 원래 타입으로 쿼리 인자를 복원해야 BIGINT UNSIGNED 등의 비교가 정확합니다
+type CursorValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func newCursorValue(v interface{}) (*CursorValue, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		return &CursorValue{Type: "int64", Value: strconv.FormatInt(val, 10)}, nil
+	case uint64:
+		return &CursorValue{Type: "uint64", Value: strconv.FormatUint(val, 10)}, nil
+	case float64:
+		return &CursorValue{Type: "float64", Value: strconv.FormatFloat(val, 'g', -1, 64)}, nil
+	case float32:
+		return &CursorValue{Type: "float64", Value: strconv.FormatFloat(float64(val), 'g', -1, 32)}, nil
+	case time.Time:
+		return &CursorValue{Type: "time", Value: val.Format(time.RFC3339Nano)}, nil
+	case []byte:
+		return &CursorValue{Type: "bytes", Value: string(val)}, nil
+	case string:
+		return &CursorValue{Type: "string", Value: val}, nil
+	default:
+		return nil, fmt.Errorf("체크포인트에 저장할 수 없는 커서 값 타입: %T", v)
+	}
+}
+
+// Arg 쿼리 인자로 사용할 원래 타입의 값을 반환합니다
+func (c *CursorValue) Arg() (interface{}, error) {
+	if c == nil {
+		return nil, nil
+	}
+	switch c.Type {
+	case "int64":
+		return strconv.ParseInt(c.Value, 10, 64)
+	case "uint64":
+		return strconv.ParseUint(c.Value, 10, 64)
+	case "float64":
+		return strconv.ParseFloat(c.Value, 64)
+	case "time":
+		return time.Parse(time.RFC3339Nano, c.Value)
+	case "bytes":
+		return []byte(c.Value), nil
+	case "string":
+		return c.Value, nil
+	default:
+		return nil, fmt.Errorf("알 수 없는 커서 값 타입: %s", c.Type)
+	}
+}
+
+// TableCheckpoint 테이블 하나의 백업 진행 상태
+type TableCheckpoint struct {
+	Status      string         `json:"status"`
+	Part        string         `json:"part"` // 테이블 SQL이 기록되는 파트 파일 (작업 디렉토리 기준)
+	Method      string         `json:"method,omitempty"`
+	OrderColumn string         `json:"order_column,omitempty"`
+	LastValue   *CursorValue   `json:"last_value,omitempty"` // 마지막으로 기록된 배치의 커서 값
+	Rows        int64          `json:"rows"`
+	Bytes       int64          `json:"bytes"`              // 파트 파일에서 유효한 바이트 수
+	Checksum    *TableChecksum `json:"checksum,omitempty"` // 완료된 테이블(파티션)의 청크 체크섬 (BACKUP_CHECKSUM=on, 이어서 백업할 때 푸터에 다시 기록)
+}
+
+// Checkpoint 백업 진행 상태를 디스크에 저장해 중단된 백업을 이어서 할 수 있게 합니다
+// 완료된 테이블과 진행 중인 커서 테이블의 마지막 커서 값, 기록된 바이트 수를 보관합니다
+type Checkpoint struct {
+	mu   sync.Mutex
+	path string
+
+	Database  string                      `json:"database"`
+	Timestamp string                      `json:"timestamp"` // 백업 파일명에 쓰이는 타임스탬프
+	Format    string                      `json:"format"`
+	Settings  string                      `json:"settings"` // 파트 파일 내용을 바꾸는 설정의 해시 (outputSettings)
+	StartedAt time.Time                   `json:"started_at"`
+	UpdatedAt time.Time                   `json:"updated_at"`
+	Tables    map[string]*TableCheckpoint `json:"tables"`
+}
+
+func NewCheckpoint(path, database, timestamp, format, settings string) *Checkpoint {
+	return &Checkpoint{
+		path:      path,
+		Database:  database,
+		Timestamp: timestamp,
+		Format:    format,
+		Settings:  settings,
+		StartedAt: time.Now(),
+		Tables:    make(map[string]*TableCheckpoint),
+	}
+}
+
+// LoadCheckpoint 체크포인트 파일을 읽습니다
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("체크포인트 읽기 실패: %v", err)
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("체크포인트 해석 실패: %v", err)
+	}
+	cp.path = path
+	if cp.Tables == nil {
+		cp.Tables = make(map[string]*TableCheckpoint)
+	}
+	return cp, nil
+}
+
+// CheckSettings 이번 실행의 출력 형식과 설정이 체크포인트를 만든 실행과 같은지 확인합니다
+// 다르면 완료된 파트 파일을 다른 형식이나 마스킹으로 기록된 채 새 백업에 이어 붙이게 되므로 이어서 하지 않습니다
+func (cp *Checkpoint) CheckSettings(format, settings string) error {
+	if cp.Format != format {
+		return fmt.Errorf("체크포인트의 출력 형식(%s)이 현재 형식(%s)과 다릅니다. 같은 BACKUP_FORMAT으로 --resume 하거나 체크포인트를 지우고 새로 백업하세요", cp.Format, format)
+	}
+	if cp.Settings != settings {
+		return fmt.Errorf("체크포인트를 만든 뒤 출력 설정이 바뀌었습니다 (마스킹 규칙, 멀티 INSERT 크기, Parquet 파일 행 수, 서버 버전). 같은 설정으로 --resume 하거나 체크포인트를 지우고 새로 백업하세요")
+	}
+	return nil
+}
+
+// outputSettings 파트 파일 내용을 바꾸는 설정의 해시 (형식은 체크포인트에 따로 기록)
+// 마스킹 규칙과 시드, 행 묶음 크기, 공간 값 리터럴 형식이 같아야 이전 실행의 파트 파일을 그대로 쓸 수 있습니다
+func (mb *MySQLBackup) outputSettings() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "multi_insert=%d\nparquet_file_rows=%d\ngeometry_axis_order=%t\nmasking=%s\n",
+		mb.config.MultiInsert, mb.config.ParquetFileRows, mb.geometryAxisOrder, mb.masker.Fingerprint())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FindLatestCheckpoint 출력 디렉토리에서 데이터베이스의 가장 최근 체크포인트를 찾습니다
+// 없으면 빈 문자열을 반환합니다
+func FindLatestCheckpoint(dir, database string) (string, error) {
+	pattern := regexp.MustCompile("^" + regexp.QuoteMeta(database) + `_backup_\d{8}_\d{6}\.checkpoint\.json$`)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		if !entry.IsDir() && pattern.MatchString(entry.Name()) {
+			matches = append(matches, entry.Name())
+		}
+	}
+	if len(matches) == 0 {
+		return "", nil
+	}
+
+	// 파일명의 타임스탬프가 정렬 가능한 형식이므로 이름순 마지막이 최신
+	sort.Strings(matches)
+	return filepath.Join(dir, matches[len(matches)-1]), nil
+}
+
+// Path 체크포인트 파일 경로
+func (c *Checkpoint) Path() string {
+	if c == nil {
+		return ""
+	}
+	return c.path
+}
+
+// Table 테이블 상태의 복사본을 반환합니다 (기록이 없으면 nil)
+func (c *Checkpoint) Table(name string) *TableCheckpoint {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tc, ok := c.Tables[name]
+	if !ok {
+		return nil
+	}
+	copied := *tc
+	return &copied
+}
+
+// StartTable 테이블을 처음부터 다시 백업하는 것으로 기록합니다
+func (c *Checkpoint) StartTable(name, part string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Tables[name] = &TableCheckpoint{Status: TableStatusInProgress, Part: part}
+	return c.save()
+}
+
+// UpdateCursor 커서 배치가 파트 파일에 기록된 후 진행 상태를 저장합니다
+func (c *Checkpoint) UpdateCursor(name, method, orderColumn string, lastValue interface{}, rows, bytes int64) error {
+	if c == nil {
+		return nil
+	}
+	cursor, err := newCursorValue(lastValue)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tc, ok := c.Tables[name]
+	if !ok {
+		return fmt.Errorf("체크포인트에 테이블 '%s'이(가) 없습니다", name)
+	}
+	tc.Method = method
+	tc.OrderColumn = orderColumn
+	tc.LastValue = cursor
+	tc.Rows = rows
+	tc.Bytes = bytes
+	return c.save()
+}
+
+// CompleteTable 테이블 백업 완료를 기록합니다 (체크섬을 계산하지 않았으면 checksum은 nil)
+func (c *Checkpoint) CompleteTable(name, method string, rows, bytes int64, checksum *TableChecksum) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tc, ok := c.Tables[name]
+	if !ok {
+		return fmt.Errorf("체크포인트에 테이블 '%s'이(가) 없습니다", name)
+	}
+	tc.Status = TableStatusCompleted
+	tc.Method = method
+	tc.LastValue = nil
+	tc.Rows = rows
+	tc.Bytes = bytes
+	tc.Checksum = checksum
+	return c.save()
+}
+
+// Save 현재 상태를 저장합니다
+func (c *Checkpoint) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+// save 임시 파일에 쓴 뒤 이름을 바꿔 중간에 죽어도 체크포인트가 깨지지 않게 합니다 (잠금 보유 상태에서 호출)
+func (c *Checkpoint) save() error {
+	c.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("체크포인트 직렬화 실패: %v", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("체크포인트 쓰기 실패: %v", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("체크포인트 교체 실패: %v", err)
+	}
+	return nil
+}
+
+// Remove 백업이 완료되면 체크포인트 파일을 삭제합니다
+func (c *Checkpoint) Remove() error {
+	if c == nil {
+		return nil
+	}
+	return os.Remove(c.path)
+}
+
+// partWriter 테이블 하나의 SQL을 파트 파일에 기록하고 기록한 바이트 수를 셉니다
+type partWriter struct {
+	path string
+	file *os.File
+	buf  *bufio.Writer
+	size int64
+}
+
+// openPartWriter 파트 파일을 열고 offset 이후의 내용을 잘라냅니다
+// 체크포인트 이후에 기록되었지만 확정되지 않은 부분을 버리기 위함입니다 (offset 0이면 새로 작성)
+func openPartWriter(path string, offset int64) (*partWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("파트 파일 열기 실패: %v", err)
+	}
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("파트 파일 자르기 실패: %v", err)
+	}
+	if _, err := file.Seek(offset, 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("파트 파일 위치 이동 실패: %v", err)
+	}
+
+	return &partWriter{
+		path: path,
+		file: file,
+		buf:  bufio.NewWriterSize(file, 256*1024),
+		size: offset,
+	}, nil
+}
+
+func (p *partWriter) Write(b []byte) (int, error) {
+	n, err := p.buf.Write(b)
+	p.size += int64(n)
+	return n, err
+}
+
+func (p *partWriter) WriteString(s string) (int, error) {
+	n, err := p.buf.WriteString(s)
+	p.size += int64(n)
+	return n, err
+}
+
+// Sync 버퍼를 비우고 디스크에 기록한 뒤 지금까지의 크기를 반환합니다
+func (p *partWriter) Sync() (int64, error) {
+	if err := p.buf.Flush(); err != nil {
+		return 0, err
+	}
+	if err := p.file.Sync(); err != nil {
+		return 0, err
+	}
+	return p.size, nil
+}
+
+// Path 파트 파일 경로
+func (p *partWriter) Path() string {
+	return p.path
+}
+
+// Size 지금까지 기록한 바이트 수
+func (p *partWriter) Size() int64 {
+	return p.size
+}
+
+func (p *partWriter) Close() error {
+	flushErr := p.buf.Flush()
+	closeErr := p.file.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
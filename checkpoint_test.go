@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCursorValueRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		typ   string
+	}{
+		{"uint64 max", uint64(math.MaxUint64), "uint64"},
+		{"int64 min", int64(math.MinInt64), "int64"},
+		{"int64 max", int64(math.MaxInt64), "int64"},
+		{"float64", 1234.5678, "float64"},
+		{"string", "2024-01-01 00:00:00.123456", "string"},
+		{"time", time.Date(2024, 2, 29, 23, 59, 59, 999999000, time.FixedZone("KST", 9*60*60)), "time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := newCursorValue(tt.value)
+			if err != nil {
+				t.Fatalf("newCursorValue: %v", err)
+			}
+			if cursor.Type != tt.typ {
+				t.Errorf("type = %q, want %q", cursor.Type, tt.typ)
+			}
+
+			// 체크포인트 파일을 거쳐도 같은 값으로 복원되어야 함
+			data, err := json.Marshal(cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded CursorValue
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			got, err := decoded.Arg()
+			if err != nil {
+				t.Fatalf("Arg: %v", err)
+			}
+			if want, ok := tt.value.(time.Time); ok {
+				if !got.(time.Time).Equal(want) {
+					t.Errorf("Arg() = %v, want %v", got, want)
+				}
+				return
+			}
+			if got != tt.value {
+				t.Errorf("Arg() = %#v, want %#v", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestCursorValueBytes(t *testing.T) {
+	cursor, err := newCursorValue([]byte("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cursor.Arg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.([]byte), []byte("abc")) {
+		t.Errorf("Arg() = %q, want %q", got, "abc")
+	}
+}
+
+func TestCursorValueNilAndErrors(t *testing.T) {
+	cursor, err := newCursorValue(nil)
+	if err != nil || cursor != nil {
+		t.Errorf("newCursorValue(nil) = %v, %v, want nil, nil", cursor, err)
+	}
+	if got, err := cursor.Arg(); got != nil || err != nil {
+		t.Errorf("nil Arg() = %v, %v, want nil, nil", got, err)
+	}
+	if _, err := newCursorValue(struct{}{}); err == nil {
+		t.Error("newCursorValue(struct{}{}) succeeded, want error")
+	}
+	if _, err := (&CursorValue{Type: "decimal", Value: "1"}).Arg(); err == nil {
+		t.Error("Arg() with unknown type succeeded, want error")
+	}
+	if _, err := (&CursorValue{Type: "uint64", Value: "-1"}).Arg(); err == nil {
+		t.Error("Arg() with negative uint64 succeeded, want error")
+	}
+}
+
+func TestCheckpointSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shop_backup_20240101_000000.checkpoint.json")
+	cp := NewCheckpoint(path, "shop", "20240101_000000", FormatSQL, "settings")
+	if err := cp.StartTable("orders", "00000_orders.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.UpdateCursor("orders", "integer_pk_cursor", "id", uint64(math.MaxUint64), 10, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.StartTable("users", "00001_users.sql"); err != nil {
+		t.Fatal(err)
+	}
+	checksum := &TableChecksum{Table: "users", Columns: []string{"id"}, Chunks: []ChunkChecksum{{Rows: 3, CRC: 42}}}
+	if err := cp.CompleteTable("users", "simple", 3, 50, checksum); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders := loaded.Table("orders")
+	if orders == nil || orders.Status != TableStatusInProgress || orders.Rows != 10 || orders.Bytes != 100 {
+		t.Fatalf("orders = %+v", orders)
+	}
+	if last, err := orders.LastValue.Arg(); err != nil || last != uint64(math.MaxUint64) {
+		t.Errorf("orders last value = %v, %v", last, err)
+	}
+	users := loaded.Table("users")
+	if users == nil || users.Status != TableStatusCompleted || users.LastValue != nil {
+		t.Fatalf("users = %+v", users)
+	}
+	if users.Checksum == nil || len(users.Checksum.Chunks) != 1 || users.Checksum.Chunks[0].CRC != 42 {
+		t.Errorf("users checksum = %+v", users.Checksum)
+	}
+	if loaded.Table("missing") != nil {
+		t.Error("Table(missing) != nil")
+	}
+}
+
+func TestCheckpointSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shop.checkpoint.json")
+	cp := NewCheckpoint(path, "shop", "20240101_000000", FormatCSV, "abc")
+	if err := cp.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.CheckSettings(FormatCSV, "abc"); err != nil {
+		t.Errorf("same settings: %v", err)
+	}
+	if err := loaded.CheckSettings(FormatSQL, "abc"); err == nil {
+		t.Error("different format accepted")
+	}
+	if err := loaded.CheckSettings(FormatCSV, "def"); err == nil {
+		t.Error("different settings accepted")
+	}
+	// 설정이 기록되지 않은 이전 버전의 체크포인트는 이어서 하지 않음
+	if err := (&Checkpoint{}).CheckSettings(FormatSQL, "abc"); err == nil {
+		t.Error("checkpoint without settings accepted")
+	}
+}
+
+func TestOutputSettings(t *testing.T) {
+	newBackup := func(multiInsert int, masker *Masker, axisOrder bool) *MySQLBackup {
+		return &MySQLBackup{config: &BackupConfig{MultiInsert: multiInsert}, masker: masker, geometryAxisOrder: axisOrder}
+	}
+	masker := func(seed, column string) *Masker {
+		m := testMasker(seed)
+		m.rules["users"] = map[string]*MaskRule{column: {Type: MaskHash}}
+		return m
+	}
+
+	base := newBackup(100, masker("s", "email"), true).outputSettings()
+	if again := newBackup(100, masker("s", "email"), true).outputSettings(); again != base {
+		t.Error("same settings gave different hashes")
+	}
+	for name, mb := range map[string]*MySQLBackup{
+		"multi insert": newBackup(50, masker("s", "email"), true),
+		"masking seed": newBackup(100, masker("t", "email"), true),
+		"masking rule": newBackup(100, masker("s", "phone"), true),
+		"no masking":   newBackup(100, nil, true),
+		"axis order":   newBackup(100, masker("s", "email"), false),
+	} {
+		if mb.outputSettings() == base {
+			t.Errorf("%s change not reflected in the settings hash", name)
+		}
+	}
+}
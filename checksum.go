@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// checksumMarker 백업 푸터에 테이블마다 한 줄씩 기록하는 청크 체크섬 표시
+const checksumMarker = "-- GOBACK-CHECKSUM: "
+
+// ChunkChecksum 순서 컬럼 범위 (Lower, Upper] 하나의 행 수와 체크섬
+type ChunkChecksum struct {
+	Partition string       `json:"partition,omitempty"` // 파티션 단위로 백업한 청크는 그 파티션 안의 범위
+	Lower     *CursorValue `json:"lower"`               // 이 값보다 큰 행부터 (nil이면 처음부터)
+	Upper     *CursorValue `json:"upper"`               // 이 값까지 (nil이면 끝까지)
+	Rows      int64        `json:"rows"`
+	CRC       uint64       `json:"crc"` // 행마다 CRC32를 구해 BIT_XOR로 합친 값
+}
+
+// TableChecksum 테이블 하나의 청크 체크섬 (순서 컬럼이 없으면 테이블 전체가 청크 하나)
+type TableChecksum struct {
+	Table       string          `json:"table"`
+	OrderColumn string          `json:"order_column,omitempty"`
+	Columns     []string        `json:"columns"`
+	Chunks      []ChunkChecksum `json:"chunks"`
+	Missing     bool            `json:"missing,omitempty"` // 체크섬을 계산하지 못한 부분이 있음 (check와 restore-test에서 실패)
+}
+
+// ChecksumSet 백업 중 워커들이 계산한 테이블별 청크 체크섬 (BACKUP_CHECKSUM=on)
+// 체크섬을 계산하지 않으면 nil이며, nil이면 모든 메서드가 아무것도 하지 않습니다
+type ChecksumSet struct {
+	mu     sync.Mutex
+	tables map[string]*TableChecksum
+}
+
+func NewChecksumSet() *ChecksumSet {
+	return &ChecksumSet{tables: make(map[string]*TableChecksum)}
+}
+
+// Start 테이블의 체크섬을 처음부터 다시 모읍니다
+// 파티션 단위는 같은 테이블의 다른 파티션이 모은 청크를 지우지 않습니다
+func (s *ChecksumSet) Start(tableName, partition, orderColumn string, columns []string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start(tableName, partition, orderColumn, columns)
+}
+
+// start 잠금 보유 상태에서 테이블 항목을 만들거나 (파티션 단위면) 기존 항목을 이어서 씁니다
+func (s *ChecksumSet) start(tableName, partition, orderColumn string, columns []string) *TableChecksum {
+	table, ok := s.tables[tableName]
+	if !ok || partition == "" {
+		table = &TableChecksum{Table: tableName}
+		s.tables[tableName] = table
+	}
+	table.OrderColumn, table.Columns = orderColumn, columns
+	return table
+}
+
+// Restore 체크포인트에 저장된 단위(테이블 또는 파티션)의 체크섬을 되살립니다 (이전 실행에서 완료된 단위를 건너뛸 때)
+func (s *ChecksumSet) Restore(tableName, partition string, saved *TableChecksum) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table := s.start(tableName, partition, saved.OrderColumn, saved.Columns)
+	table.Chunks = append(table.Chunks, saved.Chunks...)
+	table.Missing = table.Missing || saved.Missing
+}
+
+// MarkMissing 테이블에 체크섬을 계산하지 못한 부분이 있다고 표시합니다
+// 푸터에 그대로 기록되어 check와 restore-test가 검증된 것으로 보지 않게 합니다
+func (s *ChecksumSet) MarkMissing(tableName string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table, ok := s.tables[tableName]
+	if !ok {
+		table = &TableChecksum{Table: tableName}
+		s.tables[tableName] = table
+	}
+	table.Missing = true
+}
+
+// Unit 단위(테이블 또는 파티션)의 체크섬 복사본 (체크포인트 저장용, 계산하지 않았으면 nil)
+func (s *ChecksumSet) Unit(tableName, partition string) *TableChecksum {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table := s.tables[tableName]
+	if table == nil {
+		return nil
+	}
+	unit := &TableChecksum{Table: table.Table, OrderColumn: table.OrderColumn, Columns: table.Columns, Missing: table.Missing}
+	for _, chunk := range table.Chunks {
+		if chunk.Partition == partition {
+			unit.Chunks = append(unit.Chunks, chunk)
+		}
+	}
+	return unit
+}
+
+// Add 테이블에 청크 하나를 덧붙입니다
+func (s *ChecksumSet) Add(tableName string, chunk ChunkChecksum) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if table := s.tables[tableName]; table != nil {
+		table.Chunks = append(table.Chunks, chunk)
+	}
+}
+
+// Enabled 체크섬을 계산하는지 여부
+func (s *ChecksumSet) Enabled() bool {
+	return s != nil
+}
+
+// FooterLines 성공한 테이블의 체크섬을 백업 순서대로 푸터에 넣을 주석 줄로 만듭니다
+// 체크섬이 없는 성공한 테이블은 건너뛰지 않고 missing으로 기록합니다
+func (s *ChecksumSet) FooterLines(results []TableBackupResult) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var b strings.Builder
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+		table := s.tables[result.TableName]
+		if table == nil {
+			table = &TableChecksum{Table: result.TableName, Missing: true}
+		}
+		data, err := json.Marshal(table)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(checksumMarker + string(data) + "\n")
+	}
+	return b.String(), nil
+}
+
+// checksumQuery 범위 안 행의 수와 체크섬을 서버에서 계산하는 쿼리
+// 컬럼 값은 문자셋 변환 없이 바이트로 비교하고, CONCAT_WS가 건너뛰는 NULL은 ISNULL 목록으로 구분합니다
+func checksumQuery(tableName, partition, orderColumn string, columns []string, lower, upper bool) string {
+	values := make([]string, len(columns))
+	nulls := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = fmt.Sprintf("CAST(`%s` AS BINARY)", column)
+		nulls[i] = fmt.Sprintf("ISNULL(`%s`)", column)
+	}
+	query := fmt.Sprintf("SELECT COUNT(*), COALESCE(BIT_XOR(CRC32(CONCAT_WS('#', %s, CONCAT(%s)))), 0) FROM `%s`",
+		strings.Join(values, ", "), strings.Join(nulls, ", "), tableName)
+	if partition != "" {
+		query += fmt.Sprintf(" PARTITION (`%s`)", partition)
+	}
+
+	var conditions []string
+	if lower {
+		conditions = append(conditions, fmt.Sprintf("`%s` > ?", orderColumn))
+	}
+	if upper {
+		conditions = append(conditions, fmt.Sprintf("`%s` <= ?", orderColumn))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return query
+}
+
+// computeChunkChecksum 범위 (lower, upper]의 체크섬을 계산합니다 (nil이면 그 쪽 경계 없음, partition이 있으면 그 파티션 안에서)
+func computeChunkChecksum(ctx context.Context, db *sql.DB, tableName, partition, orderColumn string, columns []string, lower, upper interface{}) (ChunkChecksum, error) {
+	chunk := ChunkChecksum{Partition: partition}
+	var args []interface{}
+	if lower != nil {
+		args = append(args, lower)
+	}
+	if upper != nil {
+		args = append(args, upper)
+	}
+	query := checksumQuery(tableName, partition, orderColumn, columns, lower != nil, upper != nil)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&chunk.Rows, &chunk.CRC); err != nil {
+		return chunk, fmt.Errorf("테이블 '%s' 체크섬 계산 실패: %v", tableName, err)
+	}
+
+	var err error
+	if chunk.Lower, err = newCursorValue(lower); err != nil {
+		return chunk, err
+	}
+	if chunk.Upper, err = newCursorValue(upper); err != nil {
+		return chunk, err
+	}
+	return chunk, nil
+}
+
+// chunkChecksum 백업 중 청크 체크섬을 계산해 기록합니다 (데이터 쿼리와 같은 동시 쿼리 제한을 받음)
+// 서버에서 따로 계산하므로 백업하는 동안 바뀐 행이 있으면 백업 데이터와 달라질 수 있습니다
+func (mb *MySQLBackup) chunkChecksum(ctx context.Context, sink *tableSink, orderColumn string, lower, upper interface{}) error {
+	if !mb.checksums.Enabled() {
+		return nil
+	}
+	if len(sink.columns) == 0 {
+		mb.checksums.MarkMissing(sink.tableName)
+		return nil
+	}
+	release, err := mb.throttle.AcquireQuery(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	chunk, err := computeChunkChecksum(ctx, mb.db, sink.tableName, sink.partition, orderColumn, sink.columns, lower, upper)
+	if err != nil {
+		return err
+	}
+	mb.checksums.Add(sink.tableName, chunk)
+	return nil
+}
+
+// startChecksums 커서 방식 테이블의 체크섬을 시작합니다 (컬럼 목록을 모르면 계산하지 않고 missing으로 표시)
+// 체크포인트에서 이어서 백업하면 이미 기록된 범위 (처음, lastValue]를 청크 하나로 계산합니다
+func (mb *MySQLBackup) startChecksums(ctx context.Context, sink *tableSink, orderColumn string, lastValue interface{}) error {
+	if !mb.checksums.Enabled() {
+		return nil
+	}
+	if len(sink.columns) == 0 {
+		mb.checksums.MarkMissing(sink.tableName)
+		return nil
+	}
+	mb.checksums.Start(sink.tableName, sink.partition, orderColumn, sink.columns)
+	if lastValue == nil {
+		return nil
+	}
+	return mb.chunkChecksum(ctx, sink, orderColumn, nil, lastValue)
+}
+
+// tableChecksum 순서 컬럼 없이 읽은 테이블(단순/스트리밍)의 체크섬을 테이블(파티션 단위는 파티션) 전체 청크 하나로 계산합니다
+func (mb *MySQLBackup) tableChecksum(ctx context.Context, sink *tableSink) error {
+	if !mb.checksums.Enabled() {
+		return nil
+	}
+	if len(sink.columns) == 0 {
+		mb.checksums.MarkMissing(sink.tableName)
+		return nil
+	}
+	mb.checksums.Start(sink.tableName, sink.partition, "", sink.columns)
+	return mb.chunkChecksum(ctx, sink, "", nil, nil)
+}
+
+// parseChecksumLine 푸터의 체크섬 줄 하나를 읽습니다 (체크섬 줄이 아니면 ok가 false)
+func parseChecksumLine(line string) (TableChecksum, bool, error) {
+	var table TableChecksum
+	data, ok := strings.CutPrefix(line, checksumMarker)
+	if !ok {
+		return table, false, nil
+	}
+	if err := json.Unmarshal([]byte(data), &table); err != nil {
+		return table, false, fmt.Errorf("체크섬 줄을 읽을 수 없습니다: %v", err)
+	}
+	return table, true, nil
+}
+
+// readChecksums 백업의 푸터(디렉토리 형식은 load.sql)에서 청크 체크섬을 읽습니다
+// 체크섬 줄은 파일 끝의 주석 블록에 있으므로 큰 덤프 전체를 읽지 않고 끝에서부터 그 블록만 읽습니다
+func readChecksums(path string) ([]TableChecksum, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, "load.sql")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	footer, err := readFooterComments(file)
+	if err != nil {
+		return nil, err
+	}
+	var tables []TableChecksum
+	for _, line := range strings.Split(string(footer), "\n") {
+		if table, ok, err := parseChecksumLine(line); err != nil {
+			return nil, err
+		} else if ok {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("%s: 체크섬이 없습니다 (BACKUP_CHECKSUM=on으로 만든 백업이 아님)", path)
+	}
+	return tables, nil
+}
+
+// readFooterComments 파일 끝에 이어진 주석 줄 블록(체크섬 줄과 완료 표시)을 끝에서부터 1MB씩 거꾸로 읽어 반환합니다
+// 주석이 아닌 줄(푸터의 SET FOREIGN_KEY_CHECKS=1;)을 만나면 멈추며, 체크섬 줄이 길어도 줄 길이 제한이 없습니다
+func readFooterComments(file *os.File) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size()
+	var tail []byte // 파일의 [offset, 끝) 부분
+	kept := 0       // tail 끝에서 주석 줄로 확인한 바이트 수
+	for {
+		rest := bytes.TrimSuffix(tail[:len(tail)-kept], []byte("\n"))
+		i := bytes.LastIndexByte(rest, '\n')
+		if i < 0 && offset > 0 {
+			// 줄의 시작이 아직 읽지 않은 앞부분에 있음
+			n := min(int64(1024*1024), offset)
+			offset -= n
+			block := make([]byte, n, n+int64(len(tail)))
+			if _, err := file.ReadAt(block, offset); err != nil {
+				return nil, err
+			}
+			tail = append(block, tail...)
+			continue
+		}
+		if line := rest[i+1:]; len(line) > 0 && !bytes.HasPrefix(line, []byte("--")) {
+			return tail[len(tail)-kept:], nil
+		}
+		kept = len(tail) - (i + 1)
+		if i < 0 {
+			return tail, nil
+		}
+	}
+}
+
+// ChunkMismatch 백업에 기록된 값과 다시 계산한 값이 다른 청크
+type ChunkMismatch struct {
+	Range       string `json:"range"`
+	Rows        int64  `json:"rows"`
+	CurrentRows int64  `json:"current_rows"`
+	CRC         uint64 `json:"crc"`
+	CurrentCRC  uint64 `json:"current_crc"`
+}
+
+func (m ChunkMismatch) String() string {
+	return fmt.Sprintf("%s: 행 %d → %d, 체크섬 %d → %d", m.Range, m.Rows, m.CurrentRows, m.CRC, m.CurrentCRC)
+}
+
+// checkTable 테이블의 모든 청크를 대상 데이터베이스에서 다시 계산해 다른 청크를 반환합니다
+func checkTable(ctx context.Context, db *sql.DB, table TableChecksum) ([]ChunkMismatch, error) {
+	var mismatches []ChunkMismatch
+	for _, chunk := range table.Chunks {
+		lower, err := chunk.Lower.Arg()
+		if err != nil {
+			return nil, err
+		}
+		upper, err := chunk.Upper.Arg()
+		if err != nil {
+			return nil, err
+		}
+		current, err := computeChunkChecksum(ctx, db, table.Table, chunk.Partition, table.OrderColumn, table.Columns, lower, upper)
+		if err != nil {
+			return nil, err
+		}
+		if current.Rows == chunk.Rows && current.CRC == chunk.CRC {
+			continue
+		}
+		mismatches = append(mismatches, ChunkMismatch{
+			Range:       chunkRange(table.OrderColumn, chunk),
+			Rows:        chunk.Rows,
+			CurrentRows: current.Rows,
+			CRC:         chunk.CRC,
+			CurrentCRC:  current.CRC,
+		})
+	}
+	return mismatches, nil
+}
+
+// chunkRange 보고서에 표시할 청크 범위 (예: id (1000, 2000], 파티션 p1 id (1000, 2000])
+func chunkRange(orderColumn string, chunk ChunkChecksum) string {
+	prefix := ""
+	if chunk.Partition != "" {
+		prefix = "파티션 " + chunk.Partition + " "
+	}
+	if orderColumn == "" {
+		return prefix + "전체"
+	}
+	lower, upper := "처음", "끝"
+	if chunk.Lower != nil {
+		lower = chunk.Lower.Value
+	}
+	if chunk.Upper != nil {
+		upper = chunk.Upper.Value
+	}
+	return fmt.Sprintf("%s%s (%s, %s]", prefix, orderColumn, lower, upper)
+}
+
+// runCheck check 하위 명령: 백업에 기록된 청크 체크섬을 라이브 데이터베이스나 복원한 사본에서 다시 계산해 비교합니다
+// 종료 코드는 모두 같으면 0, 다른 청크나 없는 테이블이 있으면 exitCodeDifferent, 오류면 exitCodeFailure입니다
+func runCheck(ctx context.Context, config *BackupConfig, args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "사용법: %s check <백업> [live | live:<데이터베이스>]\n", filepath.Base(os.Args[0]))
+		fmt.Fprintln(flags.Output(), "  <백업>: BACKUP_CHECKSUM=on으로 만든 goback 백업 파일(.sql)이나 디렉토리")
+		fmt.Fprintln(flags.Output(), "  대상을 생략하면 MYSQL_* 설정의 데이터베이스 (복원한 사본은 live:<사본 이름>)")
+	}
+	flags.Parse(args)
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return exitCodeFailure
+	}
+
+	tables, err := readChecksums(flags.Arg(0))
+	if err != nil {
+		slog.Error("체크섬을 읽을 수 없습니다", "backup", flags.Arg(0), "error", err)
+		return exitCodeFailure
+	}
+
+	target := *config
+	if database, ok := strings.CutPrefix(flags.Arg(1), "live:"); ok {
+		target.Database = database
+	} else if flags.NArg() == 2 && flags.Arg(1) != "live" {
+		flags.Usage()
+		return exitCodeFailure
+	}
+	mb := NewMySQLBackup(&target)
+	if err := mb.Connect(ctx); err != nil {
+		slog.Error("데이터베이스 연결 실패", "error", err)
+		return exitCode(err)
+	}
+	defer mb.Close()
+
+	fmt.Printf("체크섬 비교: %s → %s:%s/%s\n", flags.Arg(0), target.Host, target.Port, target.Database)
+	mismatchedTables, mismatchedChunks, chunks := 0, 0, 0
+	for _, table := range tables {
+		chunks += len(table.Chunks)
+		if table.Missing {
+			// 체크섬이 빠진 범위는 검증할 수 없으므로 같다고 보지 않음
+			fmt.Printf("✗ 테이블 `%s`: 백업에 체크섬이 기록되지 않은 부분이 있습니다\n", table.Table)
+			mismatchedTables++
+			continue
+		}
+		mismatches, err := checkTable(ctx, mb.db, table)
+		if err != nil {
+			if ctx.Err() != nil {
+				return exitCodeInterrupted
+			}
+			// 테이블이 없거나 컬럼이 바뀌면 계산할 수 없으므로 테이블 전체가 다른 것으로 보고
+			fmt.Printf("✗ 테이블 `%s`: %v\n", table.Table, err)
+			mismatchedTables++
+			continue
+		}
+		for _, mismatch := range mismatches {
+			fmt.Printf("✗ 테이블 `%s` %s\n", table.Table, mismatch)
+		}
+		if len(mismatches) > 0 {
+			mismatchedTables++
+			mismatchedChunks += len(mismatches)
+		}
+	}
+
+	fmt.Printf("요약: 테이블 %d개 중 %d개 다름, 청크 %d개 중 %d개 다름\n", len(tables), mismatchedTables, chunks, mismatchedChunks)
+	if mismatchedTables > 0 {
+		return exitCodeDifferent
+	}
+	return 0
+}
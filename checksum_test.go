@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func checksumLine(t *testing.T, table TableChecksum) string {
+	t.Helper()
+	data, err := json.Marshal(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return checksumMarker + string(data) + "\n"
+}
+
+func TestReadChecksums(t *testing.T) {
+	dir := t.TempDir()
+	// 체크섬 블록보다 앞의 본문에 있는 주석 줄은 읽지 않아야 함
+	body := checksumMarker + `{"table":"stale"}` + "\n" +
+		strings.Repeat("INSERT INTO `t` VALUES (1);\n", 100000)
+	long := TableChecksum{Table: "wide", Columns: []string{strings.Repeat("c", 3*1024*1024)}}
+	footer := "SET FOREIGN_KEY_CHECKS=1;\n" +
+		checksumLine(t, TableChecksum{Table: "users", OrderColumn: "id", Columns: []string{"id"}, Chunks: []ChunkChecksum{{Rows: 2, CRC: 7}}}) +
+		checksumLine(t, long) +
+		checksumLine(t, TableChecksum{Table: "logs", Missing: true}) +
+		"-- 백업 완료"
+
+	path := filepath.Join(dir, "shop.sql")
+	if err := os.WriteFile(path, []byte(body+footer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := readChecksums(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 3 || tables[0].Table != "users" || tables[1].Table != "wide" || tables[2].Table != "logs" {
+		t.Fatalf("tables = %+v", tables)
+	}
+	if tables[0].Chunks[0].CRC != 7 || len(tables[1].Columns[0]) != len(long.Columns[0]) || !tables[2].Missing {
+		t.Errorf("checksum contents not preserved: users %+v, logs %+v", tables[0], tables[2])
+	}
+
+	// 디렉토리 형식은 load.sql의 푸터를 읽음
+	backupDir := filepath.Join(dir, "shop_csv")
+	if err := os.Mkdir(backupDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(backupDir, "load.sql"), []byte(footer+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if tables, err := readChecksums(backupDir); err != nil || len(tables) != 3 {
+		t.Errorf("directory backup: %d tables, err = %v", len(tables), err)
+	}
+
+	for name, content := range map[string]string{
+		"empty.sql":    "",
+		"nosum.sql":    body + "SET FOREIGN_KEY_CHECKS=1;\n-- 백업 완료\n",
+		"stale.sql":    body,
+		"comments.sql": "-- 주석만\n-- 있음\n",
+	} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readChecksums(p); err == nil {
+			t.Errorf("readChecksums(%s) succeeded, want error", name)
+		}
+	}
+}
+
+func TestChecksumSetPartitionsAndResume(t *testing.T) {
+	s := NewChecksumSet()
+	s.Start("events", "p0", "id", []string{"id"})
+	s.Add("events", ChunkChecksum{Partition: "p0", Rows: 1, CRC: 1})
+	s.Start("events", "p1", "id", []string{"id"})
+	s.Add("events", ChunkChecksum{Partition: "p1", Rows: 2, CRC: 2})
+
+	p1 := s.Unit("events", "p1")
+	if p1 == nil || len(p1.Chunks) != 1 || p1.Chunks[0].Rows != 2 {
+		t.Fatalf("Unit(p1) = %+v", p1)
+	}
+
+	// 재개: 체크포인트에서 p0만 되살리고 p1은 다시 계산
+	resumed := NewChecksumSet()
+	resumed.Restore("events", "p0", s.Unit("events", "p0"))
+	resumed.Start("events", "p1", "id", []string{"id"})
+	resumed.Add("events", ChunkChecksum{Partition: "p1", Rows: 2, CRC: 2})
+	// 체크섬이 저장되지 않은 이전 체크포인트의 완료 테이블
+	resumed.MarkMissing("users")
+
+	results := []TableBackupResult{{TableName: "events"}, {TableName: "users"}, {TableName: "orders"}, {TableName: "failed", Error: errors.New("boom")}}
+	lines, err := resumed.FooterLines(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []TableChecksum
+	for _, line := range strings.Split(strings.TrimSuffix(lines, "\n"), "\n") {
+		table, ok, err := parseChecksumLine(line)
+		if !ok || err != nil {
+			t.Fatalf("parseChecksumLine(%q) = %v, %v", line, ok, err)
+		}
+		tables = append(tables, table)
+	}
+	if len(tables) != 3 {
+		t.Fatalf("footer has %d tables, want 3:\n%s", len(tables), lines)
+	}
+	if tables[0].Table != "events" || len(tables[0].Chunks) != 2 || tables[0].Missing {
+		t.Errorf("events = %+v, want both partitions", tables[0])
+	}
+	if tables[1].Table != "users" || !tables[1].Missing || tables[2].Table != "orders" || !tables[2].Missing {
+		t.Errorf("tables without checksums must be recorded as missing: %+v", tables[1:])
+	}
+
+	var disabled *ChecksumSet
+	disabled.Start("t", "", "", nil)
+	disabled.MarkMissing("t")
+	if lines, _ := disabled.FooterLines(results); lines != "" || disabled.Enabled() || disabled.Unit("t", "") != nil {
+		t.Error("nil ChecksumSet should do nothing")
+	}
+}
+
+func TestChecksumQueryAndRange(t *testing.T) {
+	got := checksumQuery("events", "p1", "id", []string{"id", "name"}, true, true)
+	want := "SELECT COUNT(*), COALESCE(BIT_XOR(CRC32(CONCAT_WS('#', CAST(`id` AS BINARY), CAST(`name` AS BINARY), " +
+		"CONCAT(ISNULL(`id`), ISNULL(`name`))))), 0) FROM `events` PARTITION (`p1`) WHERE `id` > ? AND `id` <= ?"
+	if got != want {
+		t.Errorf("checksumQuery =\n%s\nwant\n%s", got, want)
+	}
+	if got := checksumQuery("t", "", "", []string{"a"}, false, false); strings.Contains(got, "WHERE") || strings.Contains(got, "PARTITION") {
+		t.Errorf("whole-table query = %s", got)
+	}
+
+	upper := &CursorValue{Type: "int64", Value: "2000"}
+	if got := chunkRange("id", ChunkChecksum{Partition: "p1", Upper: upper}); got != "파티션 p1 id (처음, 2000]" {
+		t.Errorf("chunkRange = %s", got)
+	}
+	if got := chunkRange("", ChunkChecksum{}); got != "전체" {
+		t.Errorf("chunkRange without order column = %s", got)
+	}
+}
@@ -1,11 +1,11 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"runtime"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,11 +13,8 @@ import (
 // LoadConfigFromEnv 환경변수에서 설정을 읽어옵니다
 // .env 파일이 있으면 먼저 로드합니다
 func LoadConfigFromEnv() *BackupConfig {
-	// .env 파일 로드 (있는 경우)
-	if err := godotenv.Load(); err != nil {
-		// .env 파일이 없어도 계속 진행
-		fmt.Println("💡 .env 파일을 찾을 수 없습니다. 환경변수를 사용합니다.")
-	}
+	// .env 파일 로드 (있는 경우, 없어도 계속 진행)
+	envErr := godotenv.Load()
 
 	config := &BackupConfig{
 		Host:        getEnvOrDefault("MYSQL_HOST", "localhost"),
@@ -29,11 +26,118 @@ func LoadConfigFromEnv() *BackupConfig {
 		Workers:     getEnvIntOrDefault("BACKUP_WORKERS", runtime.NumCPU()),
 		BatchSize:   getEnvIntOrDefault("BACKUP_BATCH_SIZE", 50000),
 		MultiInsert: getEnvIntOrDefault("BACKUP_MULTI_INSERT", 1000),
+		Format:      getEnvOrDefault("BACKUP_FORMAT", FormatSQL),
+
+		ParquetFileRows: getEnvIntOrDefault("BACKUP_PARQUET_FILE_ROWS", 0),
+
+		MetricsFile: getEnvOrDefault("BACKUP_METRICS_FILE", ""),
+		MetricsAddr: getEnvOrDefault("BACKUP_METRICS_ADDR", ""),
+		Interval:    getEnvDurationOrDefault("BACKUP_INTERVAL", 0),
+
+		LogFormat: getEnvOrDefault("BACKUP_LOG_FORMAT", "text"),
+		LogLevel:  getEnvOrDefault("BACKUP_LOG_LEVEL", "info"),
+
+		FailurePolicy: getEnvOrDefault("BACKUP_FAILURE_POLICY", FailurePolicyFailFast),
+
+		MaskingRules: getEnvOrDefault("BACKUP_MASKING_RULES", ""),
+		MaskingSeed:  getEnvOrDefault("BACKUP_MASKING_SEED", ""),
+
+		SubsetFile: getEnvOrDefault("BACKUP_SUBSET", ""),
+
+		ForeignKeyChecks: getEnvOrDefault("BACKUP_FOREIGN_KEY_CHECKS", "off"),
+
+		CreateDatabase: getEnvOrDefault("BACKUP_CREATE_DATABASE", "off"),
+
+		Checksum: getEnvOrDefault("BACKUP_CHECKSUM", "off"),
+
+		Partitions: getEnvOrDefault("BACKUP_PARTITIONS", "off"),
+
+		RestoreHost:     getEnvOrDefault("RESTORE_TEST_HOST", ""),
+		RestorePort:     getEnvOrDefault("RESTORE_TEST_PORT", ""),
+		RestoreUsername: getEnvOrDefault("RESTORE_TEST_USERNAME", ""),
+		RestorePassword: getEnvOrDefault("RESTORE_TEST_PASSWORD", ""),
+
+		Users:        getEnvOrDefault("BACKUP_USERS", "off"),
+		UserPatterns: getEnvOrDefault("BACKUP_USERS_PATTERN", ""),
+
+		MaxRowsPerSec:      getEnvIntOrDefault("BACKUP_MAX_ROWS_PER_SEC", 0),
+		MaxBytesPerSec:     getEnvIntOrDefault("BACKUP_MAX_BYTES_PER_SEC", 0),
+		MaxQueries:         getEnvIntOrDefault("BACKUP_MAX_QUERIES", 0),
+		PauseStatus:        getEnvOrDefault("BACKUP_PAUSE_STATUS", "Threads_running"),
+		PauseThreshold:     getEnvIntOrDefault("BACKUP_PAUSE_THRESHOLD", 0),
+		PauseCheckInterval: getEnvDurationOrDefault("BACKUP_PAUSE_CHECK_INTERVAL", 5*time.Second),
+
+		Replica:              getEnvOrDefault("BACKUP_REPLICA", "off"),
+		ReplicaMaxLag:        getEnvDurationOrDefault("BACKUP_REPLICA_MAX_LAG", 60*time.Second),
+		ReplicaCheckInterval: getEnvDurationOrDefault("BACKUP_REPLICA_CHECK_INTERVAL", 5*time.Second),
+		ReplicaMaxWait:       getEnvDurationOrDefault("BACKUP_REPLICA_MAX_WAIT", 30*time.Minute),
+		ReplicaStopSQLThread: getEnvOrDefault("BACKUP_REPLICA_STOP_SQL_THREAD", "off"),
+
+		RetryMax:        getEnvIntOrDefault("BACKUP_RETRY_MAX", 5),
+		RetryBackoff:    getEnvDurationOrDefault("BACKUP_RETRY_BACKOFF", time.Second),
+		RetryMaxBackoff: getEnvDurationOrDefault("BACKUP_RETRY_MAX_BACKOFF", 30*time.Second),
+
+		Progress:         getEnvOrDefault("BACKUP_PROGRESS", "auto"),
+		ProgressInterval: getEnvDurationOrDefault("BACKUP_PROGRESS_INTERVAL", 10*time.Second),
+	}
+
+	// 로그 설정이 정해졌으므로 기본 로거 구성 (로그는 stderr로 출력)
+	slog.SetDefault(NewLogger(os.Stderr, config.LogFormat, config.LogLevel))
+
+	if envErr != nil {
+		slog.Debug(".env 파일을 찾을 수 없습니다. 환경변수를 사용합니다.")
+	}
+
+	if config.FailurePolicy != FailurePolicyFailFast && config.FailurePolicy != FailurePolicyContinue {
+		slog.Warn("알 수 없는 실패 처리 정책입니다. fail-fast를 사용합니다.", "policy", config.FailurePolicy)
+		config.FailurePolicy = FailurePolicyFailFast
+	}
+
+	if _, ok := formatExtensions[config.Format]; !ok {
+		slog.Warn("알 수 없는 출력 형식입니다. sql을 사용합니다.", "format", config.Format)
+		config.Format = FormatSQL
+	}
+
+	if config.ForeignKeyChecks != "on" && config.ForeignKeyChecks != "off" {
+		slog.Warn("알 수 없는 외래 키 검사 설정입니다. off를 사용합니다.", "value", config.ForeignKeyChecks)
+		config.ForeignKeyChecks = "off"
+	}
+	if config.ForeignKeyChecks == "on" && config.FailurePolicy == FailurePolicyContinue {
+		slog.Warn("continue 정책에서는 실패한 부모 테이블이 빠질 수 있어 외래 키 검사를 켠 백업이 복원되지 않을 수 있습니다")
+	}
+
+	if config.CreateDatabase != "on" && config.CreateDatabase != "off" {
+		slog.Warn("알 수 없는 데이터베이스 생성 설정입니다. off를 사용합니다.", "value", config.CreateDatabase)
+		config.CreateDatabase = "off"
+	}
+
+	if config.Checksum != "on" && config.Checksum != "off" {
+		slog.Warn("알 수 없는 체크섬 설정입니다. off를 사용합니다.", "value", config.Checksum)
+		config.Checksum = "off"
+	}
+
+	if config.Partitions != "on" && config.Partitions != "off" {
+		slog.Warn("알 수 없는 파티션별 백업 설정입니다. off를 사용합니다.", "value", config.Partitions)
+		config.Partitions = "off"
+	}
+
+	if config.Users != "on" && config.Users != "off" {
+		slog.Warn("알 수 없는 계정 백업 설정입니다. off를 사용합니다.", "value", config.Users)
+		config.Users = "off"
+	}
+
+	if config.Replica != "on" && config.Replica != "off" {
+		slog.Warn("알 수 없는 레플리카 모드 설정입니다. off를 사용합니다.", "value", config.Replica)
+		config.Replica = "off"
+	}
+	if config.ReplicaStopSQLThread != "on" && config.ReplicaStopSQLThread != "off" {
+		slog.Warn("알 수 없는 SQL 스레드 정지 설정입니다. off를 사용합니다.", "value", config.ReplicaStopSQLThread)
+		config.ReplicaStopSQLThread = "off"
 	}
 
 	// 데이터베이스 이름이 비어있으면 경고
 	if config.Database == "" {
-		log.Println("⚠️ 데이터베이스 이름이 설정되지 않았습니다. 명령행 인수로 지정해주세요.")
+		slog.Warn("데이터베이스 이름이 설정되지 않았습니다. 명령행 인수로 지정해주세요.")
 	}
 
 	return config
@@ -56,3 +160,13 @@ func getEnvIntOrDefault(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvDurationOrDefault 환경변수에서 기간값(예: 30s, 1h)을 가져오거나 기본값을 반환합니다
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ForeignKey 같은 데이터베이스 안의 외래 키 하나 (복합 키는 컬럼 순서대로)
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// String 로그에 표시할 형식 (테이블.제약조건 → 참조 테이블)
+func (fk ForeignKey) String() string {
+	return fmt.Sprintf("%s.%s → %s", fk.Table, fk.Name, fk.RefTable)
+}
+
+// AddConstraintSQL 데이터를 모두 기록한 뒤 외래 키를 다시 추가하는 ALTER TABLE 문
+func (fk ForeignKey) AddConstraintSQL() string {
+	return fmt.Sprintf("ALTER TABLE `%s` ADD CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s) ON DELETE %s ON UPDATE %s;",
+		fk.Table, fk.Name, quoteColumns(fk.Columns), fk.RefTable, quoteColumns(fk.RefColumns), fk.OnDelete, fk.OnUpdate)
+}
+
+// loadForeignKeys INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS에서 데이터베이스의 외래 키를 조회합니다
+// 다른 데이터베이스를 참조하는 외래 키는 백업 순서에 영향을 주지 않으므로 제외합니다
+func (mb *MySQLBackup) loadForeignKeys(ctx context.Context) ([]ForeignKey, error) {
+	query := `
+		SELECT rc.CONSTRAINT_NAME, rc.TABLE_NAME, k.COLUMN_NAME, rc.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+			rc.UPDATE_RULE, rc.DELETE_RULE
+		FROM INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+		JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+			ON k.CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+			AND k.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+			AND k.TABLE_NAME = rc.TABLE_NAME
+		WHERE rc.CONSTRAINT_SCHEMA = ?
+		AND rc.UNIQUE_CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+		ORDER BY rc.TABLE_NAME, rc.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+
+	rows, err := mb.db.QueryContext(ctx, query, mb.config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("외래 키 조회 실패: %v", err)
+	}
+	defer rows.Close()
+
+	var fks []ForeignKey
+	for rows.Next() {
+		var name, table, column, refTable, refColumn, onUpdate, onDelete string
+		if err := rows.Scan(&name, &table, &column, &refTable, &refColumn, &onUpdate, &onDelete); err != nil {
+			return nil, fmt.Errorf("외래 키 스캔 실패: %v", err)
+		}
+
+		// 같은 제약 조건의 다음 컬럼이면 이어 붙임
+		if n := len(fks); n > 0 && fks[n-1].Table == table && fks[n-1].Name == name {
+			fks[n-1].Columns = append(fks[n-1].Columns, column)
+			fks[n-1].RefColumns = append(fks[n-1].RefColumns, refColumn)
+			continue
+		}
+		fks = append(fks, ForeignKey{
+			Name:       name,
+			Table:      table,
+			Columns:    []string{column},
+			RefTable:   refTable,
+			RefColumns: []string{refColumn},
+			OnUpdate:   onUpdate,
+			OnDelete:   onDelete,
+		})
+	}
+	return fks, rows.Err()
+}
+
+// sortTablesByDependencies 참조되는 테이블이 먼저 오도록 위상 정렬합니다 (의존 관계가 없으면 원래 순서 유지)
+// 순환 참조가 있으면 아직 배치되지 않은 부모가 가장 적은 테이블부터 배치해 순환을 끊고,
+// 정렬 후에도 앞쪽 또는 자기 자신을 참조하는 외래 키(자기 참조 포함)를 지연 대상으로 반환합니다
+// 지연된 외래 키는 CREATE TABLE에서 빼고 모든 데이터를 기록한 뒤 ALTER TABLE로 추가해야 합니다
+func sortTablesByDependencies(tables []string, fks []ForeignKey) ([]string, []ForeignKey) {
+	known := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		known[table] = true
+	}
+
+	// 자식 → 부모 (자기 참조와 다른 데이터베이스 테이블 제외)
+	parents := make(map[string]map[string]bool)
+	for _, fk := range fks {
+		if !known[fk.Table] || !known[fk.RefTable] || fk.Table == fk.RefTable {
+			continue
+		}
+		if parents[fk.Table] == nil {
+			parents[fk.Table] = make(map[string]bool)
+		}
+		parents[fk.Table][fk.RefTable] = true
+	}
+
+	placed := make(map[string]bool, len(tables))
+	unplacedParents := func(table string) int {
+		count := 0
+		for parent := range parents[table] {
+			if !placed[parent] {
+				count++
+			}
+		}
+		return count
+	}
+
+	// inCycle 아직 배치되지 않은 부모를 따라 올라가 자기 자신으로 돌아오는지 확인
+	inCycle := func(table string) bool {
+		visited := make(map[string]bool)
+		stack := []string{table}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for parent := range parents[current] {
+				if placed[parent] {
+					continue
+				}
+				if parent == table {
+					return true
+				}
+				if !visited[parent] {
+					visited[parent] = true
+					stack = append(stack, parent)
+				}
+			}
+		}
+		return false
+	}
+
+	ordered := make([]string, 0, len(tables))
+	for len(ordered) < len(tables) {
+		next := ""
+		for _, table := range tables {
+			if !placed[table] && unplacedParents(table) == 0 {
+				next = table
+				break
+			}
+		}
+		// 모든 테이블이 배치되지 않은 부모를 기다리면 순환에 속한 테이블 중 하나를 먼저 배치
+		if next == "" {
+			fewest := -1
+			for _, table := range tables {
+				if placed[table] || !inCycle(table) {
+					continue
+				}
+				if count := unplacedParents(table); fewest < 0 || count < fewest {
+					next, fewest = table, count
+				}
+			}
+		}
+		placed[next] = true
+		ordered = append(ordered, next)
+	}
+
+	position := make(map[string]int, len(ordered))
+	for i, table := range ordered {
+		position[table] = i
+	}
+	var deferred []ForeignKey
+	for _, fk := range fks {
+		if known[fk.Table] && known[fk.RefTable] && position[fk.Table] <= position[fk.RefTable] {
+			deferred = append(deferred, fk)
+		}
+	}
+	return ordered, deferred
+}
+
+// stripForeignKeys SHOW CREATE TABLE 결과에서 지정한 외래 키 정의를 제거합니다
+func stripForeignKeys(createSQL string, fks []ForeignKey) string {
+	if len(fks) == 0 {
+		return createSQL
+	}
+
+	lines := strings.Split(createSQL, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		removed := false
+		for _, fk := range fks {
+			if strings.HasPrefix(trimmed, fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY", fk.Name)) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, line)
+		}
+	}
+
+	// 마지막 정의 줄의 쉼표 정리 (닫는 괄호 바로 앞 줄)
+	for i := len(kept) - 1; i > 0; i-- {
+		if strings.HasPrefix(kept[i], ")") {
+			kept[i-1] = strings.TrimSuffix(kept[i-1], ",")
+			break
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func fk(name, table, column, refTable string) ForeignKey {
+	return ForeignKey{Name: name, Table: table, Columns: []string{column}, RefTable: refTable, RefColumns: []string{"id"},
+		OnUpdate: "RESTRICT", OnDelete: "CASCADE"}
+}
+
+// checkDependencyOrder 지연되지 않은 외래 키는 모두 부모가 자식보다 먼저 오는지 확인합니다
+func checkDependencyOrder(t *testing.T, tables, ordered []string, fks, deferred []ForeignKey) {
+	t.Helper()
+	position := make(map[string]int)
+	for i, table := range ordered {
+		if _, dup := position[table]; dup {
+			t.Fatalf("table %s appears twice in %v", table, ordered)
+		}
+		position[table] = i
+	}
+	if len(ordered) != len(tables) {
+		t.Fatalf("ordered = %v, want all of %v", ordered, tables)
+	}
+	isDeferred := make(map[string]bool)
+	for _, d := range deferred {
+		isDeferred[d.Table+"."+d.Name] = true
+	}
+	for _, f := range fks {
+		_, childKnown := position[f.Table]
+		_, parentKnown := position[f.RefTable]
+		if !childKnown || !parentKnown || isDeferred[f.Table+"."+f.Name] {
+			continue
+		}
+		if position[f.RefTable] >= position[f.Table] {
+			t.Errorf("%s placed before its parent %s: %v", f.Table, f.RefTable, ordered)
+		}
+	}
+}
+
+func TestSortTablesByDependencies(t *testing.T) {
+	tests := []struct {
+		name         string
+		tables       []string
+		fks          []ForeignKey
+		want         []string // nil이면 순서 불변식만 확인
+		wantDeferred int
+	}{
+		{
+			name:   "no foreign keys keeps order",
+			tables: []string{"c", "a", "b"},
+			want:   []string{"c", "a", "b"},
+		},
+		{
+			name:   "chain",
+			tables: []string{"order_items", "orders", "users"},
+			fks: []ForeignKey{
+				fk("fk_items_order", "order_items", "order_id", "orders"),
+				fk("fk_orders_user", "orders", "user_id", "users"),
+			},
+			want: []string{"users", "orders", "order_items"},
+		},
+		{
+			name:         "self reference is deferred",
+			tables:       []string{"categories", "products"},
+			fks:          []ForeignKey{fk("fk_parent", "categories", "parent_id", "categories"), fk("fk_category", "products", "category_id", "categories")},
+			want:         []string{"categories", "products"},
+			wantDeferred: 1,
+		},
+		{
+			name:   "two table cycle with dependent",
+			tables: []string{"c", "a", "b"},
+			fks: []ForeignKey{
+				fk("fk_ab", "a", "b_id", "b"),
+				fk("fk_ba", "b", "a_id", "a"),
+				fk("fk_ca", "c", "a_id", "a"),
+			},
+			wantDeferred: 1,
+		},
+		{
+			name:   "three table cycle",
+			tables: []string{"x", "y", "z", "w"},
+			fks: []ForeignKey{
+				fk("fk_xy", "x", "y_id", "y"),
+				fk("fk_yz", "y", "z_id", "z"),
+				fk("fk_zx", "z", "x_id", "x"),
+				fk("fk_xw", "x", "w_id", "w"),
+			},
+			wantDeferred: 1,
+		},
+		{
+			name:   "reference to unknown table is ignored",
+			tables: []string{"a", "b"},
+			fks:    []ForeignKey{fk("fk_other", "a", "x_id", "other_db_table")},
+			want:   []string{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, deferred := sortTablesByDependencies(tt.tables, tt.fks)
+			if tt.want != nil && !reflect.DeepEqual(ordered, tt.want) {
+				t.Errorf("ordered = %v, want %v", ordered, tt.want)
+			}
+			if len(deferred) != tt.wantDeferred {
+				t.Errorf("deferred = %v, want %d", deferred, tt.wantDeferred)
+			}
+			checkDependencyOrder(t, tt.tables, ordered, tt.fks, deferred)
+		})
+	}
+}
+
+func TestStripForeignKeys(t *testing.T) {
+	createSQL := "CREATE TABLE `categories` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `parent_id` int DEFAULT NULL,\n" +
+		"  `owner_id` int DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  CONSTRAINT `fk_owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`),\n" +
+		"  CONSTRAINT `fk_parent` FOREIGN KEY (`parent_id`) REFERENCES `categories` (`id`) ON DELETE SET NULL\n" +
+		") ENGINE=InnoDB"
+
+	last := stripForeignKeys(createSQL, []ForeignKey{{Name: "fk_parent"}})
+	wantLast := "CREATE TABLE `categories` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `parent_id` int DEFAULT NULL,\n" +
+		"  `owner_id` int DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  CONSTRAINT `fk_owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`)\n" +
+		") ENGINE=InnoDB"
+	if last != wantLast {
+		t.Errorf("removing the last definition:\n%s\nwant:\n%s", last, wantLast)
+	}
+
+	both := stripForeignKeys(createSQL, []ForeignKey{{Name: "fk_owner"}, {Name: "fk_parent"}})
+	wantBoth := "CREATE TABLE `categories` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `parent_id` int DEFAULT NULL,\n" +
+		"  `owner_id` int DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB"
+	if both != wantBoth {
+		t.Errorf("removing both:\n%s\nwant:\n%s", both, wantBoth)
+	}
+
+	// 이름이 다른 제약 조건의 접두어인 경우에도 정확한 이름만 제거
+	if got := stripForeignKeys(createSQL, []ForeignKey{{Name: "fk"}}); got != createSQL {
+		t.Errorf("prefix name removed a constraint:\n%s", got)
+	}
+	if got := stripForeignKeys(createSQL, nil); got != createSQL {
+		t.Error("no foreign keys changed the statement")
+	}
+}
+
+func TestAddConstraintSQL(t *testing.T) {
+	f := ForeignKey{
+		Name: "fk_order_user", Table: "orders", Columns: []string{"tenant_id", "user_id"},
+		RefTable: "users", RefColumns: []string{"tenant_id", "id"}, OnUpdate: "RESTRICT", OnDelete: "SET NULL",
+	}
+	want := "ALTER TABLE `orders` ADD CONSTRAINT `fk_order_user` FOREIGN KEY (`tenant_id`, `user_id`) " +
+		"REFERENCES `users` (`tenant_id`, `id`) ON DELETE SET NULL ON UPDATE RESTRICT;"
+	if got := f.AddConstraintSQL(); got != want {
+		t.Errorf("AddConstraintSQL() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,557 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 출력 형식
+const (
+	FormatSQL = "sql" // 하나의 SQL 덤프 파일 (INSERT 문)
+	FormatCSV = "csv" // 디렉토리: 테이블별 CREATE TABLE 파일 + RFC 4180 CSV + LOAD DATA 스크립트
+	FormatTSV = "tsv" // 디렉토리: 테이블별 CREATE TABLE 파일 + MySQL 탭 구분 형식 + LOAD DATA 스크립트
+
+	FormatParquet = "parquet" // 디렉토리: 테이블별 CREATE TABLE 파일 + Apache Parquet 파일 (데이터 레이크 스냅샷용)
+	FormatJSONL   = "jsonl"   // 디렉토리: 테이블별 CREATE TABLE 파일 + 컬럼 스키마(JSON) + 한 줄에 행 하나인 JSON Lines
+)
+
+// formatExtensions 형식별 데이터 파일 확장자
+var formatExtensions = map[string]string{
+	FormatSQL: ".sql",
+	FormatCSV: ".csv",
+	FormatTSV: ".tsv",
+
+	FormatParquet: ".parquet",
+	FormatJSONL:   ".jsonl",
+}
+
+// isDirectoryFormat 테이블마다 구조 파일과 데이터 파일을 따로 쓰는 디렉토리 형식인지 여부
+func isDirectoryFormat(format string) bool {
+	return format != FormatSQL
+}
+
+// appendableFormat 파트 파일 중간(체크포인트 위치)부터 이어 쓸 수 있는 형식인지 여부
+// Parquet은 파일 끝의 메타데이터가 있어야 읽을 수 있으므로 중단되면 테이블을 처음부터 다시 백업합니다
+func appendableFormat(format string) bool {
+	return format != FormatParquet
+}
+
+// restorableFormat mysql 클라이언트로 다시 불러오는 형식인지 여부 (Parquet/JSON Lines는 UTF-8 텍스트가 필요한 소비용)
+func restorableFormat(format string) bool {
+	return format == FormatSQL || format == FormatCSV || format == FormatTSV
+}
+
+// legacyCharset utf8mb4 연결로 읽으면 변환되는 문자셋인지 여부
+// latin1 컬럼에 다른 인코딩의 바이트를 넣어 쓰는 애플리케이션이 많아, 변환하면 복원할 때 이중 인코딩될 수 있습니다
+func legacyCharset(charset string) bool {
+	switch charset {
+	case "", "utf8mb4", "utf8mb3", "utf8", "ascii", "binary":
+		return false
+	}
+	return true
+}
+
+// dataFiles 테이블의 데이터 파일 경로 목록 (Parquet은 BACKUP_PARQUET_FILE_ROWS에 따라 여러 파일로 나뉨)
+func dataFiles(format, dataPath string) []string {
+	if format == FormatParquet {
+		return parquetFiles(dataPath)
+	}
+	return []string{dataPath}
+}
+
+// rowEncoder 스캔하고 마스킹한 행을 출력 형식에 맞게 기록합니다
+type rowEncoder interface {
+	WriteRow(values []interface{}) error
+	// Flush 배치가 끝날 때 모아둔 행을 기록합니다 (Parquet은 행 그룹 크기에 맞춰 스스로 기록)
+	Flush() error
+	// Close 남은 행과 형식의 끝 부분을 기록합니다 (파트 파일은 닫지 않음)
+	Close() error
+}
+
+// newRowEncoder 출력 형식에 맞는 인코더를 만듭니다
+// 파트 파일이 비어 있으면 CSV처럼 머리글이 있는 형식은 머리글을 먼저 기록합니다
+// multiInsert는 SQL 형식에서 INSERT 문 하나에 묶을 최대 행 수입니다
+func (mb *MySQLBackup) newRowEncoder(out *partWriter, tableName string, columns []*sql.ColumnType, multiInsert int) (rowEncoder, error) {
+	names := make([]string, len(columns))
+	kinds := make([]sqlValueKind, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name()
+		kinds[i] = sqlKind(column)
+		if kinds[i] == sqlKindGeometry && !mb.geometryAxisOrder {
+			kinds[i] = sqlKindGeometryNoAxis
+		}
+	}
+
+	switch mb.config.Format {
+	case FormatCSV:
+		enc := &csvEncoder{w: out}
+		if out.Size() == 0 {
+			if err := enc.writeHeader(names); err != nil {
+				return nil, err
+			}
+		}
+		return enc, nil
+	case FormatTSV:
+		return &tsvEncoder{w: out}, nil
+	case FormatParquet:
+		return newParquetEncoder(out, tableName, columns, mb.config.BatchSize, mb.config.ParquetFileRows)
+	case FormatJSONL:
+		return newJSONLEncoder(out, tableName, columns)
+	default:
+		if multiInsert < 1 {
+			multiInsert = 1
+		}
+		return &sqlEncoder{
+			w:           out,
+			prefix:      fmt.Sprintf("INSERT INTO `%s` (%s) VALUES ", tableName, quoteColumns(names)),
+			kinds:       kinds,
+			multiInsert: multiInsert,
+		}, nil
+	}
+}
+
+// sqlEncoder 행을 멀티 INSERT 문으로 묶어 기록합니다
+type sqlEncoder struct {
+	w           io.Writer
+	prefix      string
+	kinds       []sqlValueKind // 컬럼별 SQL 리터럴 방식
+	multiInsert int
+	batch       []string
+}
+
+func (e *sqlEncoder) WriteRow(values []interface{}) error {
+	valueStrings := make([]string, len(values))
+	for i, value := range values {
+		valueStrings[i] = formatSQLValue(value, e.kinds[i])
+	}
+	e.batch = append(e.batch, "("+strings.Join(valueStrings, ", ")+")")
+
+	// 배치가 찼으면 INSERT 문 생성
+	if len(e.batch) >= e.multiInsert {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *sqlEncoder) Flush() error {
+	return e.flush()
+}
+
+func (e *sqlEncoder) Close() error {
+	return e.flush()
+}
+
+func (e *sqlEncoder) flush() error {
+	if len(e.batch) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(e.w, e.prefix+strings.Join(e.batch, ", ")+";\n")
+	e.batch = e.batch[:0] // 슬라이스 재사용
+	return err
+}
+
+// csvEncoder RFC 4180 CSV (CRLF 줄 끝, NULL이 아닌 값은 모두 큰따옴표로 감쌈)
+// NULL은 따옴표 없는 NULL로 기록해 문자열 'NULL'과 구분합니다 (ENCLOSED BY '"'이고 이스케이프 문자가 없는 LOAD DATA가 같은 규칙으로 읽음)
+type csvEncoder struct {
+	w io.Writer
+}
+
+func (e *csvEncoder) writeHeader(names []string) error {
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = csvQuote(name)
+	}
+	_, err := io.WriteString(e.w, strings.Join(fields, ",")+"\r\n")
+	return err
+}
+
+func (e *csvEncoder) WriteRow(values []interface{}) error {
+	fields := make([]string, len(values))
+	for i, value := range values {
+		if value == nil {
+			fields[i] = "NULL"
+		} else {
+			fields[i] = csvQuote(formatTextValue(value))
+		}
+	}
+	_, err := io.WriteString(e.w, strings.Join(fields, ",")+"\r\n")
+	return err
+}
+
+func (e *csvEncoder) Flush() error {
+	return nil
+}
+
+func (e *csvEncoder) Close() error {
+	return nil
+}
+
+func csvQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// tsvEncoder SELECT ... INTO OUTFILE 기본 형식 (탭 구분, \n 줄 끝, 백슬래시 이스케이프, NULL은 \N)
+type tsvEncoder struct {
+	w io.Writer
+}
+
+// tsvEscaper LOAD DATA의 기본 ESCAPED BY '\\'가 되돌리는 문자들
+var tsvEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\t", "\\t",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\x00", "\\0",
+	"\x1a", "\\Z",
+)
+
+func (e *tsvEncoder) WriteRow(values []interface{}) error {
+	fields := make([]string, len(values))
+	for i, value := range values {
+		if value == nil {
+			fields[i] = `\N`
+		} else {
+			fields[i] = tsvEscaper.Replace(formatTextValue(value))
+		}
+	}
+	_, err := io.WriteString(e.w, strings.Join(fields, "\t")+"\n")
+	return err
+}
+
+func (e *tsvEncoder) Flush() error {
+	return nil
+}
+
+func (e *tsvEncoder) Close() error {
+	return nil
+}
+
+// jsonlEncoder 행마다 컬럼 이름을 키로 하는 JSON 객체 한 줄을 기록합니다 (키 순서는 컬럼 순서)
+type jsonlEncoder struct {
+	w     io.Writer
+	keys  [][]byte // JSON으로 인코딩한 컬럼 이름
+	kinds []string
+	line  []byte
+}
+
+// JSON Lines 값 인코딩 방식 (컬럼 스키마 파일의 encoding)
+const (
+	jsonKindNumber   = "number"   // 정수, DECIMAL, FLOAT, DOUBLE (DECIMAL은 정밀도를 잃지 않도록 원래 자릿수 그대로)
+	jsonKindBase64   = "base64"   // 바이너리 타입 (표준 base64 문자열)
+	jsonKindBit      = "bit"      // BIT(n) 값을 부호 없는 정수로
+	jsonKindGeometry = "geometry" // 공간 타입: {"srid": SRID, "wkb": WKB의 base64}
+	jsonKindDate     = "date"     // ISO-8601 날짜 (2006-01-02)
+	jsonKindDateTime = "datetime" // ISO-8601 날짜와 시각 (오프셋 없음, TIMESTAMP는 세션 시간대 +00:00 기준)
+	jsonKindJSON     = "json"     // JSON 컬럼 값을 그대로 포함
+	jsonKindString   = "string"
+)
+
+// jsonKind MySQL 컬럼 타입에 맞는 JSON 인코딩 방식
+func jsonKind(column *sql.ColumnType) string {
+	switch strings.TrimPrefix(column.DatabaseTypeName(), "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR", "DECIMAL", "FLOAT", "DOUBLE":
+		return jsonKindNumber
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return jsonKindBase64
+	case "BIT":
+		return jsonKindBit
+	case "GEOMETRY":
+		return jsonKindGeometry
+	case "DATE":
+		return jsonKindDate
+	case "DATETIME", "TIMESTAMP":
+		return jsonKindDateTime
+	case "JSON":
+		return jsonKindJSON
+	default:
+		return jsonKindString
+	}
+}
+
+// jsonlColumnSchema 컬럼 스키마 파일(.schema.json)의 컬럼 하나
+type jsonlColumnSchema struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Encoding string `json:"encoding"`
+}
+
+// newJSONLEncoder 데이터 파일 옆에 컬럼 스키마 파일을 쓰고 인코더를 만듭니다
+func newJSONLEncoder(out *partWriter, tableName string, columns []*sql.ColumnType) (*jsonlEncoder, error) {
+	enc := &jsonlEncoder{w: out}
+	schema := struct {
+		Table   string              `json:"table"`
+		Columns []jsonlColumnSchema `json:"columns"`
+	}{Table: tableName}
+
+	for _, column := range columns {
+		key, err := json.Marshal(column.Name())
+		if err != nil {
+			return nil, err
+		}
+		kind := jsonKind(column)
+		nullable, ok := column.Nullable()
+		enc.keys = append(enc.keys, key)
+		enc.kinds = append(enc.kinds, kind)
+		schema.Columns = append(schema.Columns, jsonlColumnSchema{
+			Name:     column.Name(),
+			Type:     column.DatabaseTypeName(),
+			Nullable: nullable || !ok,
+			Encoding: kind,
+		})
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(jsonlSchemaPath(out.Path()), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("컬럼 스키마 파일 기록 실패: %v", err)
+	}
+	return enc, nil
+}
+
+// jsonlSchemaPath 데이터 파일 옆에 놓이는 컬럼 스키마 파일 경로 (00003_users.jsonl -> 00003_users.schema.json)
+func jsonlSchemaPath(dataPath string) string {
+	return strings.TrimSuffix(dataPath, filepath.Ext(dataPath)) + ".schema.json"
+}
+
+func (e *jsonlEncoder) WriteRow(values []interface{}) error {
+	line := append(e.line[:0], '{')
+	for i, value := range values {
+		if i > 0 {
+			line = append(line, ',')
+		}
+		line = append(line, e.keys[i]...)
+		line = append(line, ':')
+		line = appendJSONValue(line, value, e.kinds[i])
+	}
+	line = append(line, '}', '\n')
+	e.line = line // 버퍼 재사용
+
+	_, err := e.w.Write(line)
+	return err
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return nil
+}
+
+func (e *jsonlEncoder) Close() error {
+	return nil
+}
+
+// appendJSONValue 값을 컬럼의 인코딩 방식에 맞는 JSON 값으로 덧붙입니다
+// 마스킹으로 바뀌어 숫자나 JSON으로 해석할 수 없는 값은 문자열로 기록합니다
+func appendJSONValue(b []byte, value interface{}, kind string) []byte {
+	if value == nil {
+		return append(b, "null"...)
+	}
+
+	switch kind {
+	case jsonKindNumber:
+		text := formatTextValue(value)
+		if _, err := strconv.ParseFloat(text, 64); err == nil && json.Valid([]byte(text)) {
+			return append(b, text...)
+		}
+	case jsonKindBase64:
+		if raw, ok := value.([]byte); ok {
+			return appendJSONString(b, base64.StdEncoding.EncodeToString(raw))
+		}
+		return appendJSONString(b, base64.StdEncoding.EncodeToString([]byte(formatTextValue(value))))
+	case jsonKindBit:
+		if raw, ok := value.([]byte); ok {
+			if n, ok := bitValue(raw); ok {
+				return strconv.AppendUint(b, n, 10)
+			}
+		}
+	case jsonKindGeometry:
+		// MySQL 내부 형식은 리틀엔디언 SRID 4바이트 뒤에 WKB
+		if raw, ok := value.([]byte); ok && len(raw) >= 4 {
+			b = append(b, `{"srid":`...)
+			b = strconv.AppendUint(b, uint64(binary.LittleEndian.Uint32(raw[:4])), 10)
+			b = append(b, `,"wkb":`...)
+			b = appendJSONString(b, base64.StdEncoding.EncodeToString(raw[4:]))
+			return append(b, '}')
+		}
+	case jsonKindDate:
+		if t, ok := value.(time.Time); ok {
+			return appendJSONString(b, t.Format("2006-01-02"))
+		}
+	case jsonKindDateTime:
+		if t, ok := value.(time.Time); ok {
+			return appendJSONString(b, t.Format("2006-01-02T15:04:05.999999"))
+		}
+		// 서버가 보낸 "2006-01-02 15:04:05.000000" 텍스트는 자릿수를 그대로 두고 ISO-8601 구분자만 바꿈
+		if text := formatTextValue(value); len(text) > 10 && text[10] == ' ' && !isZeroDate(text) {
+			return appendJSONString(b, text[:10]+"T"+text[11:])
+		}
+	case jsonKindJSON:
+		if raw := []byte(formatTextValue(value)); json.Valid(raw) {
+			return append(b, raw...)
+		}
+	}
+	return appendJSONString(b, formatTextValue(value))
+}
+
+func appendJSONString(b []byte, s string) []byte {
+	quoted, _ := json.Marshal(s) // 문자열 인코딩은 실패하지 않음
+	return append(b, quoted...)
+}
+
+// formatTextValue NULL이 아닌 값을 따옴표 없는 텍스트로 변환합니다 (CSV/TSV용)
+func formatTextValue(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// isZeroDate '0000-00-00'처럼 월이나 일이 0인 날짜인지 여부
+// NO_ZERO_DATE/NO_ZERO_IN_DATE가 꺼진 서버에 저장될 수 있으며, SQL/CSV/TSV에는 그대로 기록합니다
+func isZeroDate(s string) bool {
+	return len(s) >= 10 && s[4] == '-' && s[7] == '-' && (s[5:7] == "00" || s[8:10] == "00")
+}
+
+// loadDataSQL 데이터 파일을 불러오는 LOAD DATA LOCAL INFILE 문
+// 파일은 연결 문자셋(utf8mb4)으로 기록되었으므로 CHARACTER SET binary로 바이트를 변환 없이 읽습니다
+// 생성 컬럼을 뺀 데이터 파일의 필드가 테이블 컬럼과 어긋나지 않도록 columns가 있으면 컬럼 목록을 명시합니다
+func loadDataSQL(format, fileName, tableName string, columns []string) string {
+	path := "'" + escapeSQLString(fileName) + "'"
+	columnList := ""
+	if len(columns) > 0 {
+		columnList = " (" + quoteColumns(columns) + ")"
+	}
+	switch format {
+	case FormatParquet, FormatJSONL:
+		// MySQL은 Parquet/JSON Lines를 직접 읽지 못하므로 구조만 만들고 데이터 파일 위치를 남김
+		return fmt.Sprintf("-- 테이블 %s 데이터: %s (%s 파일은 LOAD DATA로 불러올 수 없습니다)", tableName, path, format)
+	case FormatCSV:
+		return fmt.Sprintf("LOAD DATA LOCAL INFILE %s INTO TABLE `%s` CHARACTER SET binary "+
+			`FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' ESCAPED BY '' LINES TERMINATED BY '\r\n' IGNORE 1 LINES%s;`,
+			path, tableName, columnList)
+	default:
+		return fmt.Sprintf("LOAD DATA LOCAL INFILE %s INTO TABLE `%s` CHARACTER SET binary "+
+			`FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n'%s;`,
+			path, tableName, columnList)
+	}
+}
+
+// rowBatch 재시도할 수 있도록 메모리에 모은 한 배치의 행 (마스킹 적용 후)
+type rowBatch struct {
+	columns   []*sql.ColumnType
+	rows      [][]interface{}
+	lastValue interface{} // 마스킹 전 원본 순서 컬럼의 마지막 값
+}
+
+// tableSink 테이블 하나의 데이터를 파트 파일에 기록합니다
+// 인코더는 첫 결과의 컬럼 정보로 만들고 테이블이 끝날 때까지 유지합니다 (Parquet 행 그룹처럼 여러 배치에 걸친 상태가 있음)
+type tableSink struct {
+	mb          *MySQLBackup
+	out         *partWriter
+	tableName   string
+	partition   string          // 이 파티션만 읽음 (비어있으면 테이블 전체)
+	columns     []string        // 데이터로 백업할 컬럼 (비어있으면 SELECT *)
+	raw         map[string]bool // 문자셋 변환 없이 바이너리로 읽을 레거시 문자셋 컬럼
+	multiInsert int
+	enc         rowEncoder
+}
+
+func (mb *MySQLBackup) newTableSink(out *partWriter, unit backupUnit, columns []string, raw map[string]bool, multiInsert int) *tableSink {
+	return &tableSink{mb: mb, out: out, tableName: unit.Table, partition: unit.Partition, columns: columns, raw: raw, multiInsert: multiInsert}
+}
+
+// from 데이터 쿼리의 FROM 절 (파티션 단위는 PARTITION 절로 그 파티션만 읽음)
+func (s *tableSink) from() string {
+	if s.partition == "" {
+		return fmt.Sprintf("`%s`", s.tableName)
+	}
+	return fmt.Sprintf("`%s` PARTITION (`%s`)", s.tableName, s.partition)
+}
+
+// name 진행률과 체크포인트에 쓰는 단위 이름
+func (s *tableSink) name() string {
+	return unitName(s.tableName, s.partition)
+}
+
+// selectList 데이터 쿼리의 SELECT 목록
+// raw 컬럼은 CAST(... AS BINARY)로 읽어 바이너리 타입으로 보이므로 0x 리터럴(SQL)이나 바이트 그대로(CSV/TSV)로 기록됩니다
+func (s *tableSink) selectList() string {
+	if len(s.columns) == 0 {
+		return "*"
+	}
+	items := make([]string, len(s.columns))
+	for i, column := range s.columns {
+		if s.raw[column] {
+			items[i] = fmt.Sprintf("CAST(`%s` AS BINARY) AS `%s`", column, column)
+		} else {
+			items[i] = fmt.Sprintf("`%s`", column)
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+// encoder 처음 호출될 때 컬럼 정보로 인코더를 만듭니다
+func (s *tableSink) encoder(columns []*sql.ColumnType) (rowEncoder, error) {
+	if s.enc == nil {
+		enc, err := s.mb.newRowEncoder(s.out, s.tableName, columns, s.multiInsert)
+		if err != nil {
+			return nil, err
+		}
+		s.enc = enc
+	}
+	return s.enc, nil
+}
+
+// WriteBatch 성공적으로 읽은 배치를 기록하고 배치 단위로 내보냅니다
+func (s *tableSink) WriteBatch(batch *rowBatch) error {
+	enc, err := s.encoder(batch.columns)
+	if err != nil {
+		return err
+	}
+	for _, values := range batch.rows {
+		if err := enc.WriteRow(values); err != nil {
+			return err
+		}
+	}
+	return enc.Flush()
+}
+
+// Close 남은 행과 형식의 끝 부분을 기록합니다
+// 결과를 한 번도 받지 못한 디렉토리 형식 테이블은 컬럼 정보만 조회해 빈 데이터 파일도 형식에 맞게 만듭니다 (CSV 머리글, Parquet 스키마)
+func (s *tableSink) Close(ctx context.Context) error {
+	if s.enc == nil {
+		if !isDirectoryFormat(s.mb.config.Format) {
+			return nil
+		}
+		rows, err := s.mb.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM `%s` LIMIT 0", s.selectList(), s.tableName))
+		if err != nil {
+			return err
+		}
+		columns, err := rows.ColumnTypes()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if _, err := s.encoder(columns); err != nil {
+			return err
+		}
+	}
+	return s.enc.Close()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVEncoder(t *testing.T) {
+	var out strings.Builder
+	enc := &csvEncoder{w: &out}
+	if err := enc.writeHeader([]string{"id", `we"ird`}); err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{int64(1), nil},
+		{int64(2), "NULL"},
+		{int64(3), []byte(`\N`)},
+		{int64(4), "a,b\r\nc \"quoted\""},
+		{int64(5), time.Date(2024, 1, 2, 3, 4, 5, 120000000, time.UTC)},
+	}
+	for _, row := range rows {
+		if err := enc.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "\"id\",\"we\"\"ird\"\r\n" +
+		"\"1\",NULL\r\n" +
+		"\"2\",\"NULL\"\r\n" +
+		"\"3\",\"\\N\"\r\n" +
+		"\"4\",\"a,b\r\nc \"\"quoted\"\"\"\r\n" +
+		"\"5\",\"2024-01-02 03:04:05.12\"\r\n"
+	if out.String() != want {
+		t.Errorf("csv output =\n%q\nwant\n%q", out.String(), want)
+	}
+}
+
+func TestTSVEncoder(t *testing.T) {
+	var out strings.Builder
+	enc := &tsvEncoder{w: &out}
+	rows := [][]interface{}{
+		{int64(1), nil},
+		{int64(2), `\N`},
+		{int64(3), "tab\there\nnew\rline"},
+		{int64(4), []byte("nul\x00ctrl\x1az\\")},
+	}
+	for _, row := range rows {
+		if err := enc.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// NULL은 \N, 문자열 "\N"은 백슬래시가 이스케이프되어 \\N이 되어야 구분됨
+	want := "1\t\\N\n" +
+		"2\t\\\\N\n" +
+		"3\ttab\\there\\nnew\\rline\n" +
+		"4\tnul\\0ctrl\\Zz\\\\\n"
+	if out.String() != want {
+		t.Errorf("tsv output =\n%q\nwant\n%q", out.String(), want)
+	}
+}
+
+func TestLoadDataSQL(t *testing.T) {
+	tests := []struct {
+		format  string
+		file    string
+		columns []string
+		want    string
+	}{
+		{
+			FormatCSV, "00001_users.csv", []string{"id", "name"},
+			"LOAD DATA LOCAL INFILE '00001_users.csv' INTO TABLE `users` CHARACTER SET binary " +
+				`FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '"' ESCAPED BY '' LINES TERMINATED BY '\r\n' IGNORE 1 LINES` +
+				" (`id`, `name`);",
+		},
+		{
+			FormatTSV, "00001_users.tsv", nil,
+			"LOAD DATA LOCAL INFILE '00001_users.tsv' INTO TABLE `users` CHARACTER SET binary " +
+				`FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n';`,
+		},
+		{
+			FormatTSV, "it's.tsv", nil,
+			"LOAD DATA LOCAL INFILE 'it\\'s.tsv' INTO TABLE `users` CHARACTER SET binary " +
+				`FIELDS TERMINATED BY '\t' ESCAPED BY '\\' LINES TERMINATED BY '\n';`,
+		},
+	}
+	for _, tt := range tests {
+		if got := loadDataSQL(tt.format, tt.file, "users", tt.columns); got != tt.want {
+			t.Errorf("loadDataSQL(%s, %s) =\n%s\nwant\n%s", tt.format, tt.file, got, tt.want)
+		}
+	}
+
+	for _, format := range []string{FormatParquet, FormatJSONL} {
+		got := loadDataSQL(format, "00001_users."+format, "users", nil)
+		if !strings.HasPrefix(got, "-- ") || strings.Contains(got, "\n") {
+			t.Errorf("loadDataSQL(%s) = %q, want a single comment line", format, got)
+		}
+	}
+}
+
+func TestAppendJSONValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		kind  string
+		want  string
+	}{
+		{nil, jsonKindNumber, `null`},
+		{nil, jsonKindString, `null`},
+		{[]byte("12345678901234567890.000001"), jsonKindNumber, `12345678901234567890.000001`},
+		{int64(-5), jsonKindNumber, `-5`},
+		{"masked", jsonKindNumber, `"masked"`},
+		{[]byte("NaN"), jsonKindNumber, `"NaN"`},
+		{[]byte{0x00, 0xff}, jsonKindBase64, `"AP8="`},
+		{[]byte{0x01, 0x00}, jsonKindBit, `256`},
+		{[]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09}, jsonKindBit, `"\u0001\u0002\u0003\u0004\u0005\u0006\u0007\b\t"`},
+		{[]byte{0xe6, 0x10, 0x00, 0x00, 0x01}, jsonKindGeometry, `{"srid":4326,"wkb":"AQ=="}`},
+		{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), jsonKindDate, `"2024-02-29"`},
+		{time.Date(2024, 2, 29, 13, 4, 5, 500000000, time.UTC), jsonKindDateTime, `"2024-02-29T13:04:05.5"`},
+		{[]byte("2024-02-29 13:04:05.000000"), jsonKindDateTime, `"2024-02-29T13:04:05.000000"`},
+		{[]byte("0000-00-00 00:00:00"), jsonKindDateTime, `"0000-00-00 00:00:00"`},
+		{[]byte(`{"a": [1, 2]}`), jsonKindJSON, `{"a": [1, 2]}`},
+		{[]byte(`{broken`), jsonKindJSON, `"{broken"`},
+		{"line\n\"quote\" <tag>", jsonKindString, `"line\n\"quote\" \u003ctag\u003e"`},
+	}
+	for _, tt := range tests {
+		got := string(appendJSONValue(nil, tt.value, tt.kind))
+		if got != tt.want {
+			t.Errorf("appendJSONValue(%#v, %s) = %s, want %s", tt.value, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestJSONLEncoderWriteRow(t *testing.T) {
+	var out strings.Builder
+	enc := &jsonlEncoder{
+		w:     &out,
+		keys:  [][]byte{[]byte(`"id"`), []byte(`"na\"me"`)},
+		kinds: []string{jsonKindNumber, jsonKindString},
+	}
+	for _, row := range [][]interface{}{{int64(1), "a"}, {int64(2), nil}} {
+		if err := enc.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := `{"id":1,"na\"me":"a"}` + "\n" + `{"id":2,"na\"me":null}` + "\n"
+	if out.String() != want {
+		t.Errorf("jsonl output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestJSONLSchemaPath(t *testing.T) {
+	if got := jsonlSchemaPath("backup/00003_users.jsonl"); got != "backup/00003_users.schema.json" {
+		t.Errorf("jsonlSchemaPath = %s", got)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// sqlValueKind INSERT 문에 값을 SQL 리터럴로 쓰는 방식 (컬럼 타입으로 결정)
+type sqlValueKind int
+
+const (
+	sqlKindString         sqlValueKind = iota // 작은따옴표 문자열
+	sqlKindBinary                             // 0x 16진수 (BINARY, VARBINARY, BLOB 계열: 올바르지 않은 UTF-8도 그대로 보존)
+	sqlKindBit                                // b'0101' 비트 값
+	sqlKindGeometry                           // ST_GeomFromWKB(WKB, SRID, 'axis-order=long-lat')
+	sqlKindGeometryNoAxis                     // ST_GeomFromWKB(WKB, SRID) (축 순서 옵션이 없는 MariaDB, MySQL 8.0.12 미만)
+	sqlKindJSON                               // _utf8mb4 문자열 (바이너리 문자열로는 JSON 값을 만들 수 없음)
+)
+
+// sqlKind MySQL 컬럼 타입에 맞는 SQL 리터럴 방식
+func sqlKind(column *sql.ColumnType) sqlValueKind {
+	switch column.DatabaseTypeName() {
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return sqlKindBinary
+	case "BIT":
+		return sqlKindBit
+	case "GEOMETRY":
+		return sqlKindGeometry
+	case "JSON":
+		return sqlKindJSON
+	default:
+		return sqlKindString
+	}
+}
+
+// formatSQLValue 스캔한 값을 INSERT 문에 들어갈 SQL 리터럴로 변환합니다
+// 마스킹으로 문자열이 된 BIT/GEOMETRY 값은 원래 형식이 아니므로 문자열 리터럴로 씁니다
+func formatSQLValue(value interface{}, kind sqlValueKind) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		switch kind {
+		case sqlKindBinary:
+			return hexLiteral(v)
+		case sqlKindBit:
+			return bitLiteral(v)
+		case sqlKindGeometry:
+			return geometryLiteral(v, true)
+		case sqlKindGeometryNoAxis:
+			return geometryLiteral(v, false)
+		case sqlKindJSON:
+			return "_utf8mb4'" + escapeSQLString(string(v)) + "'"
+		}
+		return "'" + escapeSQLString(string(v)) + "'"
+	case string:
+		if kind == sqlKindBinary {
+			return hexLiteral([]byte(v))
+		}
+		return "'" + escapeSQLString(v) + "'"
+	case time.Time:
+		return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05.999999"))
+	default:
+		return fmt.Sprintf("'%v'", v)
+	}
+}
+
+// hexLiteral 바이트를 문자셋 변환 없이 그대로 넣는 0x 리터럴 (빈 값은 빈 문자열 리터럴)
+func hexLiteral(b []byte) string {
+	if len(b) == 0 {
+		return "''"
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+// bitLiteral BIT(n) 값(빅엔디언 바이트)을 b'...' 리터럴로 변환합니다
+func bitLiteral(b []byte) string {
+	return "b'" + new(big.Int).SetBytes(b).Text(2) + "'"
+}
+
+// geometryLiteral MySQL 내부 공간 형식(리틀엔디언 SRID 4바이트 + WKB)을 ST_GeomFromWKB 호출로 변환합니다
+// 내부 형식은 SRID와 관계없이 경도-위도 순서로 저장되므로, SRID가 있으면 WKB를 같은 축 순서로 읽도록 지정합니다
+// axisOrder가 false면 옵션 인자를 받지 않는 서버용으로 SRID만 지정합니다 (축 순서 개념이 없어 항상 x-y 순서)
+func geometryLiteral(b []byte, axisOrder bool) string {
+	if len(b) < 4 {
+		return hexLiteral(b)
+	}
+	srid := binary.LittleEndian.Uint32(b[:4])
+	wkb := hexLiteral(b[4:])
+	if srid == 0 {
+		return "ST_GeomFromWKB(" + wkb + ")"
+	}
+	if !axisOrder {
+		return fmt.Sprintf("ST_GeomFromWKB(%s, %d)", wkb, srid)
+	}
+	return fmt.Sprintf("ST_GeomFromWKB(%s, %d, 'axis-order=long-lat')", wkb, srid)
+}
+
+// geometryAxisOrderSupported VERSION() 문자열로 ST_GeomFromWKB의 옵션 인자(axis-order) 지원 여부를 판단합니다
+// MySQL 8.0.12부터 지원하며 MariaDB와 5.7은 지원하지 않습니다
+func geometryAxisOrderSupported(version string) bool {
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return false
+	}
+	var major, minor, patch int
+	fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &patch)
+	if major != 8 {
+		return major > 8
+	}
+	return minor > 0 || patch >= 12
+}
+
+// bitValue BIT(n) 값(빅엔디언, 최대 8바이트)을 부호 없는 정수로 변환합니다
+func bitValue(b []byte) (uint64, bool) {
+	if len(b) > 8 {
+		return 0, false
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, true
+}
+
+// sqlStringEscaper 작은따옴표 문자열 리터럴 안에서 mysql 클라이언트가 그대로 읽을 수 있도록 바꾸는 문자들
+// (mysql_real_escape_string과 같은 규칙: NUL 바이트나 Ctrl-Z가 날것으로 있으면 클라이언트가 덤프를 잘못 읽음)
+var sqlStringEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"'", "\\'",
+	"\x00", "\\0",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\x1a", "\\Z",
+)
+
+// escapeSQLString 작은따옴표 문자열 리터럴 안에 넣을 수 있도록 이스케이프합니다
+func escapeSQLString(s string) string {
+	return sqlStringEscaper.Replace(s)
+}
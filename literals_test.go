@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestGeometryLiteral(t *testing.T) {
+	point := []byte{0x01, 0x01, 0x00, 0x00, 0x00}
+	tests := []struct {
+		value     []byte
+		axisOrder bool
+		want      string
+	}{
+		{append([]byte{0x00, 0x00, 0x00, 0x00}, point...), true, "ST_GeomFromWKB(0x0101000000)"},
+		{append([]byte{0xe6, 0x10, 0x00, 0x00}, point...), true, "ST_GeomFromWKB(0x0101000000, 4326, 'axis-order=long-lat')"},
+		{append([]byte{0xe6, 0x10, 0x00, 0x00}, point...), false, "ST_GeomFromWKB(0x0101000000, 4326)"},
+		{[]byte{0x01, 0x02}, true, "0x0102"},
+	}
+	for _, tt := range tests {
+		if got := geometryLiteral(tt.value, tt.axisOrder); got != tt.want {
+			t.Errorf("geometryLiteral(%x, %v) = %s, want %s", tt.value, tt.axisOrder, got, tt.want)
+		}
+	}
+}
+
+func TestGeometryAxisOrderSupported(t *testing.T) {
+	tests := map[string]bool{
+		"8.0.12":                     true,
+		"8.0.36-0ubuntu0.22.04.1":    true,
+		"8.4.0":                      true,
+		"9.1.0":                      true,
+		"8.0.11":                     false,
+		"5.7.44-log":                 false,
+		"10.11.6-MariaDB-0+deb12u1":  false,
+		"5.5.5-10.6.16-MariaDB-log":  false,
+		"11.4.2-MariaDB-ubu2404-log": false,
+		"":                           false,
+	}
+	for version, want := range tests {
+		if got := geometryAxisOrderSupported(version); got != want {
+			t.Errorf("geometryAxisOrderSupported(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestBitLiteralAndValue(t *testing.T) {
+	if got := bitLiteral([]byte{0x00, 0x05}); got != "b'101'" {
+		t.Errorf("bitLiteral = %s, want b'101'", got)
+	}
+	if got := bitLiteral([]byte{0x00}); got != "b'0'" {
+		t.Errorf("bitLiteral(0) = %s, want b'0'", got)
+	}
+	if n, ok := bitValue([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}); !ok || n != 1<<64-1 {
+		t.Errorf("bitValue(64 bits) = %d, %v", n, ok)
+	}
+	if _, ok := bitValue(make([]byte, 9)); ok {
+		t.Error("bitValue accepted more than 8 bytes")
+	}
+}
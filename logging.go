@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// NewLogger 설정된 형식(text/json)과 레벨로 구조화 로거를 생성합니다
+func NewLogger(w io.Writer, format, level string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLogLevel(level)}
+
+	if strings.ToLower(format) == "json" {
+		// JSON에서는 소요 시간을 나노초 정수 대신 초 단위 실수로 출력
+		opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if a.Value.Kind() == slog.KindDuration {
+				return slog.Float64(a.Key, a.Value.Duration().Seconds())
+			}
+			return a
+		}
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// parseLogLevel 로그 레벨 문자열을 해석합니다
+// quiet는 경고 이상만, verbose는 디버그까지 출력합니다
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug", "verbose":
+		return slog.LevelDebug
+	case "warn", "warning", "quiet":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
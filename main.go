@@ -2,14 +2,20 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -22,22 +28,112 @@ type BackupConfig struct {
 	Password    string
 	Database    string
 	OutputDir   string
-	Workers     int // 병렬 워커 수
-	BatchSize   int // 배치 처리 크기
-	MultiInsert int // 멀티 INSERT 문의 최대 행 수
+	Workers     int    // 병렬 워커 수
+	BatchSize   int    // 배치 처리 크기
+	MultiInsert int    // 멀티 INSERT 문의 최대 행 수
+	Format      string // 출력 형식 (sql, csv, tsv, parquet, jsonl)
+	LogFormat   string // 로그 형식 (text, json)
+	LogLevel    string // 로그 레벨 (debug/verbose, info, warn/quiet, error)
+
+	ParquetFileRows int // Parquet 파일 하나에 기록할 최대 행 수 (0이면 테이블당 파일 하나)
+
+	FailurePolicy string // 테이블 실패 처리 정책 (fail-fast, continue)
+
+	Resume bool // 가장 최근 체크포인트에서 중단된 백업을 이어서 실행
+	Stdout bool // 파일 대신 덤프 전체를 stdout으로 출력 (-o -), 로그와 진행 상황은 stderr
+
+	MaskingRules string // 컬럼 마스킹 규칙 파일 (JSON)
+	MaskingSeed  string // 마스킹 시드 (규칙 파일의 seed보다 우선)
+
+	SubsetFile string // 부분 추출 설정 파일 (JSON, 비어있으면 전체 백업)
+
+	ForeignKeyChecks string // 복원 시 외래 키 검사 (off: 헤더에서 검사 비활성화, on: 의존 순서대로 검사하며 복원 - 테이블마다 다른 시점에 읽으므로 쓰기가 없을 때만)
+
+	CreateDatabase string // 헤더에 CREATE DATABASE와 USE 기록 (off: 정의를 주석으로만 남겨 복원할 때 지정한 데이터베이스에 불러옴)
+
+	Checksum string // 청크 체크섬 (on: 커서 범위마다 서버에서 CRC32를 계산해 푸터에 기록, goback check로 비교)
+
+	Partitions string // 파티션별 병렬 백업 (on: 대용량 파티션 테이블을 파티션마다 다른 워커가 백업)
+
+	RestoreHost     string // restore-test가 백업을 복원할 서버 (비어있으면 MYSQL_* 서버)
+	RestorePort     string
+	RestoreUsername string
+	RestorePassword string
+
+	Users        string // 계정, 롤, 권한 백업 (on: SQL 덤프 끝 또는 디렉토리의 users.sql에 기록)
+	UserPatterns string // 백업할 계정의 user@host 패턴 (쉼표 구분, LIKE 문법, 비어있으면 시스템 계정을 뺀 모든 계정)
+
+	MaxRowsPerSec      int           // 모든 워커를 합친 초당 최대 행 수 (0이면 제한 없음)
+	MaxBytesPerSec     int           // 모든 워커를 합친 초당 최대 바이트 수 (0이면 제한 없음)
+	MaxQueries         int           // 동시에 실행할 최대 데이터 쿼리 수 (0이면 워커 수만큼)
+	PauseStatus        string        // 감시할 서버 상태 변수 (예: Threads_running)
+	PauseThreshold     int           // 상태 변수가 이 값을 넘으면 일시 정지 (0이면 감시 안 함)
+	PauseCheckInterval time.Duration // 상태 변수 확인 주기
+
+	Replica              string        // 레플리카 모드 (on: 복제 상태를 확인하고 소스 위치를 헤더에 기록)
+	ReplicaMaxLag        time.Duration // 이보다 복제 지연이 크면 배치를 일시 정지
+	ReplicaCheckInterval time.Duration // 복제 지연 확인 주기
+	ReplicaMaxWait       time.Duration // 복제 지연이 내려오기를 기다리는 최대 시간 (0이면 무제한)
+	ReplicaStopSQLThread string        // on이면 백업하는 동안 SQL 스레드를 멈춰 데이터를 고정
+
+	RetryMax        int           // 커서 배치당 최대 재시도 횟수 (0이면 재시도 안 함)
+	RetryBackoff    time.Duration // 첫 재시도 대기 시간 (시도마다 2배)
+	RetryMaxBackoff time.Duration // 재시도 대기 시간 상한
+
+	Progress         string        // 진행 상황 표시 (auto, tty, log, off)
+	ProgressInterval time.Duration // 로그 모드 진행 상황 출력 주기
+
+	MetricsFile string        // node_exporter textfile 수집기용 메트릭 파일 경로
+	MetricsAddr string        // /metrics HTTP 엔드포인트 주소 (예: :9101)
+	Interval    time.Duration // 데몬 모드 백업 주기 (0이면 한 번만 실행)
 }
 
+// 테이블 백업 실패 처리 정책
+const (
+	FailurePolicyFailFast = "fail-fast" // 첫 실패에서 전체 백업 중단, 파일을 남기지 않음
+	FailurePolicyContinue = "continue"  // 나머지 테이블을 계속 백업하고 불완전한 백업으로 표시
+)
+
+// ErrIncompleteBackup 일부 테이블이 누락된 채 백업 파일이 작성되었음을 나타냅니다
+var ErrIncompleteBackup = errors.New("불완전한 백업")
+
 type MySQLBackup struct {
-	config *BackupConfig
-	db     *sql.DB
+	config  *BackupConfig
+	db      *sql.DB
+	metrics *BackupMetrics
+	logger  *slog.Logger
+
+	masker     *Masker     // 컬럼 마스킹 규칙 (설정하지 않으면 nil)
+	subsetSpec *SubsetSpec // 부분 추출 설정 (설정하지 않으면 nil)
+	subset     *Subset     // 실행 중인 백업에서 선택된 행 (부분 추출이 아니면 nil)
+	checkpoint *Checkpoint // 실행 중인 백업의 체크포인트
+	workDir    string      // 테이블별 파트 파일이 기록되는 작업 디렉토리
+
+	deferredFKs map[string][]ForeignKey // 순환 참조 때문에 데이터 기록 후 추가하는 테이블별 외래 키
+
+	throttle *Throttle     // 처리량/동시 쿼리 제한과 서버 부하 감시 (설정하지 않으면 nil)
+	replica  *ReplicaGuard // 실행 중인 레플리카 모드 백업의 복제 감시 (레플리카 모드가 아니면 nil)
+
+	checksums *ChecksumSet // 실행 중인 백업의 청크 체크섬 (계산하지 않으면 nil)
+
+	geometryAxisOrder bool // 공간 값에 ST_GeomFromWKB의 axis-order 옵션을 씀 (MySQL 8.0.12 이상, 백업 시작 시 확인)
+
+	progress *ProgressTracker // 실행 중인 백업의 진행률 (표시하지 않으면 nil)
+	display  *ProgressDisplay // 터미널 실시간 표시 (TTY가 아니면 nil)
+
+	stdout io.Writer // -o - 일 때 덤프를 내보낼 곳
 }
 
 type TableBackupResult struct {
 	TableName string
+	Partition string // 파티션 단위 결과 (비어있으면 테이블 전체)
 	Error     error
-	Index     int    // 원래 순서 보존용
-	RowCount  int64  // 백업된 행 수
-	TempFile  string // 임시 파일 경로
+	Index     int           // 원래 순서 보존용
+	RowCount  int64         // 백업된 행 수
+	TempFile  string        // 임시 파일 경로
+	Method    string        // 사용된 백업 방법
+	Bytes     int64         // 생성된 SQL 크기
+	Duration  time.Duration // 백업 소요 시간
 }
 
 type TableInfo struct {
@@ -49,16 +145,23 @@ type TableInfo struct {
 	OrderColumnType  string
 	HasAutoIncrement bool
 	HasTimestamp     bool
+	Partitions       []TablePartition // 파티션 테이블의 파티션 (정의 순서)
 }
 
 func NewMySQLBackup(config *BackupConfig) *MySQLBackup {
 	return &MySQLBackup{
-		config: config,
+		config:   config,
+		metrics:  NewBackupMetrics(config.Database),
+		logger:   slog.Default().With("database", config.Database),
+		stdout:   os.Stdout,
+		throttle: NewThrottle(config),
 	}
 }
 
-func (mb *MySQLBackup) Connect() error {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4",
+func (mb *MySQLBackup) Connect(ctx context.Context) error {
+	// 날짜/시각은 서버가 보낸 텍스트 그대로 받아 소수점 이하 초와 0000-00-00 같은 값을 잃지 않음 (parseTime 사용 안 함)
+	// 세션 시간대를 헤더의 SET time_zone = "+00:00"과 맞춰 TIMESTAMP 값이 복원할 때 밀리지 않게 함
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&time_zone=%%27%%2B00%%3A00%%27",
 		mb.config.Username, mb.config.Password, mb.config.Host, mb.config.Port, mb.config.Database)
 
 	db, err := sql.Open("mysql", dsn)
@@ -72,18 +175,18 @@ func (mb *MySQLBackup) Connect() error {
 	db.SetMaxIdleConns(mb.config.Workers)
 
 	// 연결 테스트
-	if err := db.Ping(); err != nil {
-		return fmt.Errorf("데이터베이스 핑 실패: %v", err)
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("데이터베이스 핑 실패: %w", err)
 	}
 
 	mb.db = db
-	fmt.Printf("✓ 데이터베이스 '%s'에 성공적으로 연결되었습니다.\n", mb.config.Database)
+	mb.logger.Info("데이터베이스 연결 성공", "host", mb.config.Host, "port", mb.config.Port)
 	return nil
 }
 
-func (mb *MySQLBackup) GetTables() ([]string, error) {
+func (mb *MySQLBackup) GetTables(ctx context.Context) ([]string, error) {
 	query := "SHOW TABLES"
-	rows, err := mb.db.Query(query)
+	rows, err := mb.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("테이블 목록 조회 실패: %v", err)
 	}
@@ -101,8 +204,42 @@ func (mb *MySQLBackup) GetTables() ([]string, error) {
 	return tables, nil
 }
 
-func (mb *MySQLBackup) analyzeTable(tableName string) (*TableInfo, error) {
-	info := &TableInfo{Name: tableName}
+// getTableRowEstimates 전체 진행률 계산을 위해 모든 테이블의 추정 행 수를 한 번에 조회합니다
+func (mb *MySQLBackup) getTableRowEstimates(ctx context.Context) (map[string]int64, error) {
+	query := `
+		SELECT TABLE_NAME, COALESCE(TABLE_ROWS, 0)
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = ?`
+	rows, err := mb.db.QueryContext(ctx, query, mb.config.Database)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	estimates := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var estimated int64
+		if err := rows.Scan(&name, &estimated); err != nil {
+			return nil, err
+		}
+		estimates[name] = estimated
+	}
+	return estimates, rows.Err()
+}
+
+func (mb *MySQLBackup) analyzeTable(ctx context.Context, tableName string) (*TableInfo, error) {
+	// 파티션 (조회 실패 시 파티션 없는 테이블로 취급)
+	partitions, err := mb.loadPartitions(ctx, tableName)
+	if err != nil {
+		partitions = nil
+	}
+	return mb.analyzeTableWithPartitions(ctx, tableName, partitions[tableName])
+}
+
+// analyzeTableWithPartitions 이미 조회한 파티션 목록으로 테이블을 분석합니다
+func (mb *MySQLBackup) analyzeTableWithPartitions(ctx context.Context, tableName string, partitions []TablePartition) (*TableInfo, error) {
+	info := &TableInfo{Name: tableName, Partitions: partitions}
 
 	// 1. 테이블 크기 추정 (INFORMATION_SCHEMA 사용)
 	sizeQuery := `
@@ -110,15 +247,15 @@ func (mb *MySQLBackup) analyzeTable(tableName string) (*TableInfo, error) {
 		FROM INFORMATION_SCHEMA.TABLES 
 		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`
 
-	err := mb.db.QueryRow(sizeQuery, mb.config.Database, tableName).Scan(&info.EstimatedRows)
+	err := mb.db.QueryRowContext(ctx, sizeQuery, mb.config.Database, tableName).Scan(&info.EstimatedRows)
 	if err != nil {
 		info.EstimatedRows = 0 // 추정 실패시 0으로 설정
 	}
 
-	info.IsLargeTable = info.EstimatedRows > 10000
+	info.IsLargeTable = info.EstimatedRows > largeTableRows
 
 	// 2. 최적의 순서 컬럼 찾기 (우선순위: AUTO_INCREMENT > TIMESTAMP > 순차적 PK)
-	orderColumn, columnType, method := mb.findBestOrderColumn(tableName)
+	orderColumn, columnType, method := mb.findBestOrderColumn(ctx, tableName)
 	info.OrderColumn = orderColumn
 	info.OrderColumnType = columnType
 	info.OptimalMethod = method
@@ -128,10 +265,20 @@ func (mb *MySQLBackup) analyzeTable(tableName string) (*TableInfo, error) {
 	info.HasTimestamp = strings.Contains(strings.ToLower(columnType), "timestamp") ||
 		strings.Contains(strings.ToLower(columnType), "datetime")
 
+	mb.logger.Debug("테이블 분석 완료",
+		"table", tableName,
+		"estimated_rows", info.EstimatedRows,
+		"large", info.IsLargeTable,
+		"method", info.OptimalMethod,
+		"order_column", info.OrderColumn,
+		"partitions", len(info.Partitions))
+
 	return info, nil
 }
 
-func (mb *MySQLBackup) findBestOrderColumn(tableName string) (string, string, string) {
+// findBestOrderColumn 커서로 쓸 순서 컬럼을 찾습니다
+// 생성 컬럼은 데이터 쿼리의 SELECT 목록에서 빠지므로 커서 값을 얻을 수 없어 후보에서 제외합니다
+func (mb *MySQLBackup) findBestOrderColumn(ctx context.Context, tableName string) (string, string, string) {
 	// 1순위: AUTO_INCREMENT 컬럼 찾기
 	autoIncQuery := `
 		SELECT COLUMN_NAME, COLUMN_TYPE
@@ -141,7 +288,7 @@ func (mb *MySQLBackup) findBestOrderColumn(tableName string) (string, string, st
 		LIMIT 1`
 
 	var columnName, columnType string
-	err := mb.db.QueryRow(autoIncQuery, mb.config.Database, tableName).Scan(&columnName, &columnType)
+	err := mb.db.QueryRowContext(ctx, autoIncQuery, mb.config.Database, tableName).Scan(&columnName, &columnType)
 	if err == nil {
 		return columnName, columnType, "auto_increment_cursor"
 	}
@@ -155,10 +302,11 @@ func (mb *MySQLBackup) findBestOrderColumn(tableName string) (string, string, st
 		AND k.CONSTRAINT_NAME = 'PRIMARY'
 		AND c.TABLE_SCHEMA = ? AND c.TABLE_NAME = ?
 		AND c.DATA_TYPE IN ('int', 'bigint', 'smallint', 'tinyint', 'mediumint')
+		AND c.EXTRA NOT LIKE '%VIRTUAL GENERATED%' AND c.EXTRA NOT LIKE '%STORED GENERATED%'
 		ORDER BY k.ORDINAL_POSITION
 		LIMIT 1`
 
-	err = mb.db.QueryRow(pkQuery, mb.config.Database, tableName, mb.config.Database, tableName).Scan(&columnName, &columnType)
+	err = mb.db.QueryRowContext(ctx, pkQuery, mb.config.Database, tableName, mb.config.Database, tableName).Scan(&columnName, &columnType)
 	if err == nil {
 		return columnName, columnType, "integer_pk_cursor"
 	}
@@ -170,6 +318,7 @@ func (mb *MySQLBackup) findBestOrderColumn(tableName string) (string, string, st
 		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? 
 		AND (DATA_TYPE IN ('timestamp', 'datetime') 
 		     OR COLUMN_NAME IN ('created_at', 'updated_at', 'date_created', 'date_modified'))
+		AND EXTRA NOT LIKE '%VIRTUAL GENERATED%' AND EXTRA NOT LIKE '%STORED GENERATED%'
 		ORDER BY 
 			CASE 
 				WHEN COLUMN_NAME = 'created_at' THEN 1
@@ -181,7 +330,7 @@ func (mb *MySQLBackup) findBestOrderColumn(tableName string) (string, string, st
 			END
 		LIMIT 1`
 
-	err = mb.db.QueryRow(timestampQuery, mb.config.Database, tableName).Scan(&columnName, &columnType)
+	err = mb.db.QueryRowContext(ctx, timestampQuery, mb.config.Database, tableName).Scan(&columnName, &columnType)
 	if err == nil {
 		return columnName, columnType, "timestamp_cursor"
 	}
@@ -191,315 +340,466 @@ func (mb *MySQLBackup) findBestOrderColumn(tableName string) (string, string, st
 	return "_rowid", "bigint", "rowid_cursor"
 }
 
-func (mb *MySQLBackup) BackupTable(tableName string) (string, int64, error) {
-	var sqlContent strings.Builder
+// BackupTable 테이블 구조와 데이터를 out에 기록합니다
+// resume에 커서 위치가 있으면 구조와 그 이전 데이터는 이미 기록된 것으로 보고 이어서 백업합니다
+func (mb *MySQLBackup) BackupTable(ctx context.Context, unit backupUnit, out *partWriter, resume *TableCheckpoint) (int64, string, error) {
+	tableName, name := unit.Table, unit.Name()
+	resuming := resume != nil && resume.LastValue != nil
+
+	if !resuming && unit.Structure {
+		// 테이블 구조 백업
+		createTableSQL, err := mb.getCreateTableSQL(ctx, tableName)
+		if err != nil {
+			return 0, "", fmt.Errorf("테이블 구조 조회 실패: %v", err)
+		}
+
+		// 순환 참조 외래 키는 모든 데이터를 기록한 뒤 추가
+		createTableSQL = stripForeignKeys(createTableSQL, mb.deferredFKs[tableName])
 
-	// 테이블 구조 백업
-	createTableSQL, err := mb.getCreateTableSQL(tableName)
+		// 기존 테이블은 다른 테이블이 참조하고 있어도 지울 수 있도록 DROP 동안만 외래 키 검사를 끔
+		structure := fmt.Sprintf("-- 테이블 %s 구조\n", tableName) +
+			"SET @GOBACK_OLD_FK_CHECKS = @@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS = 0;\n" +
+			fmt.Sprintf("DROP TABLE IF EXISTS `%s`;\n", tableName) +
+			"SET FOREIGN_KEY_CHECKS = @GOBACK_OLD_FK_CHECKS;\n" +
+			createTableSQL + ";\n\n"
+
+		// 디렉토리 형식은 데이터 파일 옆의 별도 .sql 파일에 구조를 기록
+		if isDirectoryFormat(mb.config.Format) {
+			if err := os.WriteFile(schemaFilePath(out.Path()), []byte(structure), 0644); err != nil {
+				return 0, "", fmt.Errorf("테이블 구조 파일 기록 실패: %v", err)
+			}
+		} else {
+			out.WriteString(structure)
+		}
+	}
+
+	columns, raw, err := mb.dumpColumns(ctx, tableName)
 	if err != nil {
-		return "", 0, fmt.Errorf("테이블 구조 조회 실패: %v", err)
+		return 0, "", fmt.Errorf("컬럼 목록 조회 실패: %v", err)
 	}
 
-	sqlContent.WriteString(fmt.Sprintf("-- 테이블 %s 구조\n", tableName))
-	sqlContent.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS `%s`;\n", tableName))
-	sqlContent.WriteString(createTableSQL + ";\n\n")
+	// 부분 추출: 외래 키를 따라 선택된 행만 기록
+	if mb.subset != nil {
+		mb.progress.StartTable(tableName, mb.subset.RowCount(tableName))
+		mb.writeSQLComment(out, "-- 테이블 %s 데이터 (subset)\n", tableName)
+		sink := mb.newTableSink(out, unit, columns, raw, mb.config.MultiInsert)
+		rowCount, err := mb.getTableDataSubset(ctx, tableName, sink)
+		if err == nil {
+			err = sink.Close(ctx)
+		}
+		if err != nil {
+			return 0, "subset", fmt.Errorf("테이블 데이터 조회 실패: %v", err)
+		}
+		mb.writeSQLComment(out, "-- 테이블 %s: %d 행\n\n", tableName, rowCount)
+		return rowCount, "subset", nil
+	}
 
-	// 테이블 분석
-	tableInfo, err := mb.analyzeTable(tableName)
-	if err != nil {
-		return "", 0, fmt.Errorf("테이블 분석 실패: %v", err)
+	// 테이블 분석 (파티션 단위는 계획할 때 분석한 결과를 함께 씀)
+	tableInfo := unit.Info
+	if tableInfo == nil {
+		if tableInfo, err = mb.analyzeTable(ctx, tableName); err != nil {
+			return 0, "", fmt.Errorf("테이블 분석 실패: %v", err)
+		}
+	}
+	estimatedRows := tableInfo.EstimatedRows
+	for _, partition := range tableInfo.Partitions {
+		if partition.Name == unit.Partition {
+			estimatedRows = partition.EstimatedRows
+		}
+	}
+	mb.progress.StartTable(name, estimatedRows)
+
+	// 체크포인트의 커서 위치부터 이어서 백업 (통계가 바뀌었더라도 처음 선택한 방법을 유지)
+	if resuming {
+		lastValue, err := resume.LastValue.Arg()
+		if err != nil {
+			return 0, resume.Method, fmt.Errorf("체크포인트 커서 값 복원 실패: %v", err)
+		}
+
+		mb.logger.Info("체크포인트에서 테이블 백업을 이어갑니다",
+			"table", name, "method", resume.Method, "rows", resume.Rows, "cursor", resume.LastValue.Value)
+		mb.progress.ResumeRows(name, resume.Rows)
+
+		sink := mb.newTableSink(out, unit, columns, raw, mb.config.MultiInsert)
+		rowCount, err := mb.getTableDataCursorBased(ctx, tableName, resume.OrderColumn, resume.Method, sink, lastValue, resume.Rows)
+		if err == nil {
+			err = sink.Close(ctx)
+		}
+		if err != nil {
+			return 0, resume.Method, fmt.Errorf("테이블 데이터 조회 실패: %v", err)
+		}
+		mb.writeSQLComment(out, "-- 테이블 %s: %d 행\n\n", name, rowCount)
+		return rowCount, resume.Method, nil
 	}
 
 	// 최적 방법으로 데이터 백업
-	var dataSQL string
 	var rowCount int64
+	method := tableInfo.OptimalMethod
 
-	if !tableInfo.IsLargeTable {
+	if !tableInfo.IsLargeTable && unit.Partition == "" {
 		// 소용량: 단순한 방법이 가장 빠름
-		dataSQL, rowCount, err = mb.getTableDataSimple(tableName)
-	} else {
-		// 대용량: 최적 방법 선택
-		switch tableInfo.OptimalMethod {
-		case "auto_increment_cursor", "integer_pk_cursor":
-			dataSQL, rowCount, err = mb.getTableDataCursorBased(tableName, tableInfo.OrderColumn, "순차 커서")
-		case "timestamp_cursor":
-			dataSQL, rowCount, err = mb.getTableDataCursorBased(tableName, tableInfo.OrderColumn, "시간 커서")
-		case "rowid_cursor":
-			dataSQL, rowCount, err = mb.getTableDataRowIdBased(tableName)
-		default:
-			dataSQL, rowCount, err = mb.getTableDataStreaming(tableName)
-		}
+		// (파티션 단위는 체크섬 청크의 순서 컬럼이 같도록 파티션 크기와 관계없이 테이블의 방법을 씀)
+		method = "simple"
+	}
+	mb.writeSQLComment(out, "-- 테이블 %s 데이터 (%s)\n", name, method)
+
+	sink := mb.newTableSink(out, unit, columns, raw, mb.config.MultiInsert)
+	switch method {
+	case "simple":
+		rowCount, err = mb.getTableDataSimple(ctx, tableName, sink)
+	case "auto_increment_cursor", "integer_pk_cursor", "timestamp_cursor":
+		rowCount, err = mb.getTableDataCursorBased(ctx, tableName, tableInfo.OrderColumn, method, sink, nil, 0)
+	case "rowid_cursor":
+		rowCount, err = mb.getTableDataRowIdBased(ctx, tableName, sink)
+	default:
+		rowCount, err = mb.getTableDataStreaming(ctx, tableName, sink)
+	}
+	if err == nil {
+		err = sink.Close(ctx)
 	}
 
 	if err != nil {
-		return "", 0, fmt.Errorf("테이블 데이터 조회 실패: %v", err)
+		return 0, method, fmt.Errorf("테이블 데이터 조회 실패: %v", err)
 	}
 
-	if dataSQL != "" {
-		sqlContent.WriteString(fmt.Sprintf("-- 테이블 %s 데이터 (%d 행, %s)\n",
-			tableName, rowCount, tableInfo.OptimalMethod))
-		sqlContent.WriteString(dataSQL + "\n")
-	}
+	mb.writeSQLComment(out, "-- 테이블 %s: %d 행\n\n", name, rowCount)
 
-	return sqlContent.String(), rowCount, nil
+	return rowCount, method, nil
 }
 
-func (mb *MySQLBackup) getCreateTableSQL(tableName string) (string, error) {
-	query := fmt.Sprintf("SHOW CREATE TABLE `%s`", tableName)
-	var table, createSQL string
-	err := mb.db.QueryRow(query).Scan(&table, &createSQL)
-	if err != nil {
-		return "", err
+// writeSQLComment SQL 형식일 때만 파트 파일에 주석을 기록합니다 (CSV 등 데이터 파일에는 주석을 넣을 수 없음)
+func (mb *MySQLBackup) writeSQLComment(out *partWriter, format string, args ...interface{}) {
+	if !isDirectoryFormat(mb.config.Format) {
+		out.WriteString(fmt.Sprintf(format, args...))
 	}
-	return createSQL, nil
 }
 
-// 소용량 테이블: 기존 방식 (단순하고 빠름)
-func (mb *MySQLBackup) getTableDataSimple(tableName string) (string, int64, error) {
-	query := fmt.Sprintf("SELECT * FROM `%s`", tableName)
-	rows, err := mb.db.Query(query)
+// schemaFilePath 디렉토리 형식에서 데이터 파일 옆에 놓이는 CREATE TABLE 파일 경로
+func schemaFilePath(dataPath string) string {
+	return strings.TrimSuffix(dataPath, filepath.Ext(dataPath)) + ".sql"
+}
+
+// dumpColumns 데이터로 백업할 컬럼 목록 (정의 순서)
+// 생성 컬럼(VIRTUAL/STORED)은 복원할 때 값을 넣을 수 없으므로 빼고, SELECT *에 나오지 않는 INVISIBLE 컬럼은 명시적으로 포함합니다
+// 복원용 형식(sql/csv/tsv)에서는 utf8mb4로 바꾸면 바이트가 달라질 수 있는 레거시 문자셋 컬럼을 raw로 표시합니다 (바이너리로 읽음)
+// 조회에 실패하면 생성 컬럼이 섞인 복원 불가능한 백업이 되지 않도록 오류를 반환합니다 (테이블 실패)
+// INFORMATION_SCHEMA에 컬럼이 보이지 않으면 빈 목록을 반환합니다 (SELECT *로 백업)
+func (mb *MySQLBackup) dumpColumns(ctx context.Context, tableName string) ([]string, map[string]bool, error) {
+	query := `
+		SELECT COLUMN_NAME, EXTRA, COALESCE(GENERATION_EXPRESSION, ''), COALESCE(CHARACTER_SET_NAME, '')
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`
+	rows, err := mb.db.QueryContext(ctx, query, mb.config.Database, tableName)
 	if err != nil {
-		return "", 0, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
-	if err != nil {
-		return "", 0, err
+	var columns, generated, invisible []string
+	raw := make(map[string]bool)
+	for rows.Next() {
+		var name, extra, expression, charset string
+		if err := rows.Scan(&name, &extra, &expression, &charset); err != nil {
+			return nil, nil, err
+		}
+		if legacyCharset(charset) && restorableFormat(mb.config.Format) {
+			raw[name] = true
+		}
+		switch {
+		case isGeneratedColumn(extra, expression):
+			generated = append(generated, name)
+		case strings.Contains(strings.ToUpper(extra), "INVISIBLE"):
+			invisible = append(invisible, name)
+			columns = append(columns, name)
+		default:
+			columns = append(columns, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
 	}
 
-	var insertStatements []string
-	var rowCount int64
+	if len(generated) > 0 || len(invisible) > 0 {
+		mb.logger.Debug("컬럼 목록을 명시해 백업합니다",
+			"table", tableName, "generated_excluded", generated, "invisible_included", invisible)
+	}
+	if len(raw) > 0 {
+		mb.logger.Debug("레거시 문자셋 컬럼을 바이트 그대로 백업합니다", "table", tableName, "columns", len(raw))
+	}
+	return columns, raw, nil
+}
 
-	columnNames := make([]string, len(columns))
-	for i, col := range columns {
-		columnNames[i] = fmt.Sprintf("`%s`", col)
+// isGeneratedColumn VIRTUAL/STORED 생성 컬럼인지 여부
+// DEFAULT (expr) 컬럼도 EXTRA에 DEFAULT_GENERATED가 붙지만 값을 넣을 수 있으므로 생성 컬럼이 아닙니다
+func isGeneratedColumn(extra, expression string) bool {
+	extra = strings.ToUpper(extra)
+	if strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") ||
+		strings.Contains(extra, "PERSISTENT GENERATED") {
+		return true
 	}
+	return expression != "" && !strings.Contains(extra, "DEFAULT_GENERATED")
+}
 
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// createDatabaseSQL 헤더에 넣을 데이터베이스 정의와 USE 문 (SHOW CREATE DATABASE, 기본 문자셋/콜레이션 포함)
+// 기본값(BACKUP_CREATE_DATABASE=off)은 mysqldump(--databases 없이)처럼 USE 없이 정의를 주석으로만 남겨,
+// mysql <데이터베이스> < 백업.sql이 지정한 데이터베이스에 복원되고 원본 데이터베이스를 덮어쓰지 않게 합니다
+func (mb *MySQLBackup) createDatabaseSQL(ctx context.Context) (string, error) {
+	query := fmt.Sprintf("SHOW CREATE DATABASE IF NOT EXISTS `%s`", mb.config.Database)
+	var database, createSQL string
+	if err := mb.db.QueryRowContext(ctx, query).Scan(&database, &createSQL); err != nil {
+		return "", err
+	}
+	if mb.config.CreateDatabase == "off" {
+		return "-- " + createSQL + ";\n", nil
+	}
+	return fmt.Sprintf("%s;\nUSE `%s`;\n", createSQL, mb.config.Database), nil
+}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return "", 0, err
-		}
-
-		var valueStrings []string
-		for _, value := range values {
-			if value == nil {
-				valueStrings = append(valueStrings, "NULL")
-			} else {
-				switch v := value.(type) {
-				case []byte:
-					escaped := strings.ReplaceAll(string(v), "'", "\\'")
-					escaped = strings.ReplaceAll(escaped, "\\", "\\\\")
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", escaped))
-				case string:
-					escaped := strings.ReplaceAll(v, "'", "\\'")
-					escaped = strings.ReplaceAll(escaped, "\\", "\\\\")
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", escaped))
-				case time.Time:
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05")))
-				default:
-					valueStrings = append(valueStrings, fmt.Sprintf("'%v'", v))
-				}
-			}
-		}
+func (mb *MySQLBackup) getCreateTableSQL(ctx context.Context, tableName string) (string, error) {
+	query := fmt.Sprintf("SHOW CREATE TABLE `%s`", tableName)
+	var table, createSQL string
+	err := mb.db.QueryRowContext(ctx, query).Scan(&table, &createSQL)
+	if err != nil {
+		return "", err
+	}
+	return createSQL, nil
+}
 
-		insertSQL := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s);",
-			tableName,
-			strings.Join(columnNames, ", "),
-			strings.Join(valueStrings, ", "))
-		insertStatements = append(insertStatements, insertSQL)
-		rowCount++
+// 소용량 테이블: 기존 방식 (단순하고 빠름)
+func (mb *MySQLBackup) getTableDataSimple(ctx context.Context, tableName string, sink *tableSink) (int64, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s", sink.selectList(), sink.from())
+	rows, release, err := mb.queryData(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	rowCount, err := mb.streamRows(ctx, rows, tableName, sink)
+	rows.Close()
+	release()
+	if err != nil {
+		return 0, err
 	}
 
-	return strings.Join(insertStatements, "\n"), rowCount, nil
+	// 체크섬 쿼리도 동시 쿼리 제한을 받으므로 데이터 쿼리의 슬롯을 돌려준 뒤 계산
+	return rowCount, mb.tableChecksum(ctx, sink)
 }
 
 // 커서 기반 페이징 (AUTO_INCREMENT, 정수 PK, TIMESTAMP 등)
-func (mb *MySQLBackup) getTableDataCursorBased(tableName, orderColumn, method string) (string, int64, error) {
-	var allInserts []string
-	var rowCount int64
-	var lastValue interface{}
+// 배치가 일시적인 오류로 실패하면 마지막으로 성공한 커서 값부터 다시 시도합니다
+// 배치를 기록할 때마다 커서 위치를 체크포인트에 저장하며, lastValue/rowCount를 주면 그 위치부터 이어서 읽습니다
+func (mb *MySQLBackup) getTableDataCursorBased(ctx context.Context, tableName, orderColumn, method string, sink *tableSink, lastValue interface{}, rowCount int64) (int64, error) {
+	if err := mb.startChecksums(ctx, sink, orderColumn, lastValue); err != nil {
+		return 0, err
+	}
 
 	for {
-		var query string
-		var rows *sql.Rows
-		var err error
-
-		if lastValue == nil {
-			// 첫 번째 배치
-			query = fmt.Sprintf("SELECT * FROM `%s` ORDER BY `%s` LIMIT %d",
-				tableName, orderColumn, mb.config.BatchSize)
-			rows, err = mb.db.Query(query)
-		} else {
-			// 다음 배치들
-			query = fmt.Sprintf("SELECT * FROM `%s` WHERE `%s` > ? ORDER BY `%s` LIMIT %d",
-				tableName, orderColumn, orderColumn, mb.config.BatchSize)
-			rows, err = mb.db.Query(query, lastValue)
-		}
+		var batch *rowBatch
 
-		if err != nil {
-			return "", 0, err
+		// 레플리카 모드: 복제 지연이 너무 크면 내려갈 때까지 다음 배치를 미룸
+		if err := mb.replica.WaitForLag(ctx, tableName); err != nil {
+			return 0, err
 		}
 
-		columns, err := rows.Columns()
+		err := mb.withRetry(ctx, tableName, lastValue, func() error {
+			var err error
+			batch, err = mb.fetchCursorBatch(ctx, sink, orderColumn, lastValue)
+			return err
+		})
 		if err != nil {
-			rows.Close()
-			return "", 0, err
+			return 0, err
 		}
 
-		// 순서 컬럼의 인덱스 찾기
-		orderIndex := -1
-		for i, col := range columns {
-			if col == orderColumn {
-				orderIndex = i
-				break
-			}
+		batchCount := int64(len(batch.rows))
+		if batchCount == 0 {
+			break // 더 이상 데이터가 없음
 		}
 
-		batchInserts, batchCount, newLastValue, err := mb.processCursorRows(rows, tableName, columns, orderIndex)
-		rows.Close()
+		if err := sink.WriteBatch(batch); err != nil {
+			return 0, err
+		}
+		if err := mb.chunkChecksum(ctx, sink, orderColumn, lastValue, batch.lastValue); err != nil {
+			return 0, err
+		}
+		rowCount += batchCount
+		lastValue = batch.lastValue
+		mb.progress.AddRows(sink.name(), batchCount)
 
+		// 배치가 디스크에 기록된 뒤에 커서 위치 저장
+		size, err := sink.out.Sync()
 		if err != nil {
-			return "", 0, err
+			return 0, fmt.Errorf("파트 파일 기록 실패: %v", err)
 		}
-
-		if batchCount == 0 {
-			break // 더 이상 데이터가 없음
+		if err := mb.checkpoint.UpdateCursor(sink.name(), method, orderColumn, lastValue, rowCount, size); err != nil {
+			return 0, err
 		}
 
-		allInserts = append(allInserts, batchInserts...)
-		rowCount += batchCount
-		lastValue = newLastValue
+		mb.logger.Debug("배치 처리 완료",
+			"table", tableName, "method", method, "batch_rows", batchCount, "rows", rowCount)
 
 		if batchCount < int64(mb.config.BatchSize) {
 			break // 마지막 배치
 		}
 	}
 
-	return strings.Join(allInserts, "\n"), rowCount, nil
+	// 마지막 커서 값 뒤의 범위도 기록해 두면 백업 이후 추가된 행을 찾을 수 있음
+	if err := mb.chunkChecksum(ctx, sink, orderColumn, lastValue, nil); err != nil {
+		return 0, err
+	}
+	return rowCount, nil
 }
 
-// ROWID 기반 처리 (MySQL 8.0+)
-func (mb *MySQLBackup) getTableDataRowIdBased(tableName string) (string, int64, error) {
-	// 로깅 제거
-	// fmt.Printf("   📊 테이블 '%s': ROWID 방식으로 처리\n", tableName)
+// fetchCursorBatch lastValue 다음부터 한 배치를 읽습니다 (lastValue가 nil이면 처음부터)
+func (mb *MySQLBackup) fetchCursorBatch(ctx context.Context, sink *tableSink, orderColumn string, lastValue interface{}) (*rowBatch, error) {
+	if lastValue == nil {
+		// 첫 번째 배치
+		query := fmt.Sprintf("SELECT %s FROM %s ORDER BY `%s` LIMIT %d",
+			sink.selectList(), sink.from(), orderColumn, mb.config.BatchSize)
+		return mb.queryBatch(ctx, sink.tableName, orderColumn, query)
+	}
+
+	// 다음 배치들
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE `%s` > ? ORDER BY `%s` LIMIT %d",
+		sink.selectList(), sink.from(), orderColumn, orderColumn, mb.config.BatchSize)
+	return mb.queryBatch(ctx, sink.tableName, orderColumn, query, lastValue)
+}
+
+// queryBatch 쿼리 결과를 마스킹한 행 배치로 읽습니다
+// 재시도할 수 있도록 결과는 메모리에만 모으고, 성공한 배치만 호출한 쪽에서 파트 파일에 기록합니다
+// orderColumn이 있으면 그 컬럼의 마지막 원본 값을 배치에 담습니다
+func (mb *MySQLBackup) queryBatch(ctx context.Context, tableName, orderColumn, query string, args ...interface{}) (*rowBatch, error) {
+	rows, release, err := mb.queryData(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer rows.Close()
 
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 순서 컬럼의 인덱스 찾기
+	orderIndex := -1
+	for i, column := range columnTypes {
+		if orderColumn != "" && column.Name() == orderColumn {
+			orderIndex = i
+			break
+		}
+	}
+
+	batch := &rowBatch{columns: columnTypes}
+	_, batch.lastValue, err = mb.readRows(ctx, rows, tableName, columnTypes, orderIndex, func(values []interface{}) error {
+		batch.rows = append(batch.rows, values)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// ROWID 기반 처리 (MySQL 8.0+)
+func (mb *MySQLBackup) getTableDataRowIdBased(ctx context.Context, tableName string, sink *tableSink) (int64, error) {
 	// ROWID가 지원되는지 확인
 	testQuery := fmt.Sprintf("SELECT _rowid FROM `%s` LIMIT 1", tableName)
-	_, err := mb.db.Query(testQuery)
-	if err != nil {
+	testRows, err := mb.db.QueryContext(ctx, testQuery)
+	if err == nil {
+		testRows.Close()
+	} else if ctx.Err() != nil {
+		return 0, ctx.Err()
+	} else {
 		// ROWID 지원하지 않으면 스트리밍으로 폴백
-		// fmt.Printf("   ⚠️ ROWID 미지원, 스트리밍 방식으로 전환\n")
-		return mb.getTableDataStreaming(tableName)
+		mb.logger.Debug("ROWID 미지원, 스트리밍 방식으로 전환", "table", tableName)
+		return mb.getTableDataStreaming(ctx, tableName, sink)
 	}
 
-	return mb.getTableDataCursorBased(tableName, "_rowid", "ROWID 커서")
+	return mb.getTableDataCursorBased(ctx, tableName, "_rowid", "rowid_cursor", sink, nil, 0)
 }
 
 // 대용량 테이블 스트리밍 (최후의 수단)
-func (mb *MySQLBackup) getTableDataStreaming(tableName string) (string, int64, error) {
-	// 로깅 제거
-	// fmt.Printf("   📊 테이블 '%s': 스트리밍 방식으로 처리\n", tableName)
-
-	query := fmt.Sprintf("SELECT * FROM `%s`", tableName)
-	rows, err := mb.db.Query(query)
+func (mb *MySQLBackup) getTableDataStreaming(ctx context.Context, tableName string, sink *tableSink) (int64, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s", sink.selectList(), sink.from())
+	rows, release, err := mb.queryData(ctx, query)
 	if err != nil {
-		return "", 0, err
+		return 0, err
 	}
-	defer rows.Close()
-
-	columns, err := rows.Columns()
+	rowCount, err := mb.streamRows(ctx, rows, tableName, sink)
+	rows.Close()
+	release()
 	if err != nil {
-		return "", 0, err
+		return 0, err
 	}
 
-	var allInserts []string
-	var rowCount int64
+	// 체크섬 쿼리도 동시 쿼리 제한을 받으므로 데이터 쿼리의 슬롯을 돌려준 뒤 계산
+	return rowCount, mb.tableChecksum(ctx, sink)
+}
 
-	columnNames := make([]string, len(columns))
-	for i, col := range columns {
-		columnNames[i] = fmt.Sprintf("`%s`", col)
+// queryData 동시 쿼리 제한 슬롯을 얻은 뒤 테이블 데이터 쿼리를 실행합니다
+// 결과를 모두 읽고 rows를 닫은 뒤 release를 호출해 슬롯을 돌려줘야 합니다
+func (mb *MySQLBackup) queryData(ctx context.Context, query string, args ...interface{}) (*sql.Rows, func(), error) {
+	release, err := mb.throttle.AcquireQuery(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
+	rows, err := mb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
 
-	var currentBatch []string
-
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+// streamRows 결과를 메모리에 모으지 않고 바로 파트 파일에 기록합니다 (단순/스트리밍 방식)
+// 진행률은 multiInsert 행마다, 그리고 끝에 남은 행 수로 갱신합니다
+func (mb *MySQLBackup) streamRows(ctx context.Context, rows *sql.Rows, tableName string, sink *tableSink) (int64, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	enc, err := sink.encoder(columnTypes)
+	if err != nil {
+		return 0, err
+	}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return "", 0, err
-		}
-
-		var valueStrings []string
-		for _, value := range values {
-			if value == nil {
-				valueStrings = append(valueStrings, "NULL")
-			} else {
-				switch v := value.(type) {
-				case []byte:
-					escaped := strings.ReplaceAll(string(v), "'", "\\'")
-					escaped = strings.ReplaceAll(escaped, "\\", "\\\\")
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", escaped))
-				case string:
-					escaped := strings.ReplaceAll(v, "'", "\\'")
-					escaped = strings.ReplaceAll(escaped, "\\", "\\\\")
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", escaped))
-				case time.Time:
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05")))
-				default:
-					valueStrings = append(valueStrings, fmt.Sprintf("'%v'", v))
-				}
-			}
+	var pending int64
+	rowCount, _, err := mb.readRows(ctx, rows, tableName, columnTypes, -1, func(values []interface{}) error {
+		if err := enc.WriteRow(values); err != nil {
+			return err
 		}
-
-		valueGroup := fmt.Sprintf("(%s)", strings.Join(valueStrings, ", "))
-		currentBatch = append(currentBatch, valueGroup)
-		rowCount++
-
-		// 배치가 찼으면 INSERT 문 생성
-		if len(currentBatch) >= mb.config.MultiInsert {
-			insertSQL := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s;",
-				tableName,
-				strings.Join(columnNames, ", "),
-				strings.Join(currentBatch, ", "))
-			allInserts = append(allInserts, insertSQL)
-			currentBatch = currentBatch[:0] // 슬라이스 재사용
+		pending++
+		if pending >= int64(sink.multiInsert) {
+			mb.progress.AddRows(sink.name(), pending)
+			pending = 0
 		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
 	// 남은 배치 처리
-	if len(currentBatch) > 0 {
-		insertSQL := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s;",
-			tableName,
-			strings.Join(columnNames, ", "),
-			strings.Join(currentBatch, ", "))
-		allInserts = append(allInserts, insertSQL)
+	if err := enc.Flush(); err != nil {
+		return 0, err
 	}
-
-	return strings.Join(allInserts, "\n"), rowCount, nil
+	if pending > 0 {
+		mb.progress.AddRows(sink.name(), pending)
+	}
+	return rowCount, nil
 }
 
-func (mb *MySQLBackup) processCursorRows(rows *sql.Rows, tableName string, columns []string, orderIndex int) ([]string, int64, interface{}, error) {
-	var insertStatements []string
-	var rowCount int64
-	var lastValue interface{}
-
-	columnNames := make([]string, len(columns))
-	for i, col := range columns {
-		columnNames[i] = fmt.Sprintf("`%s`", col)
+// readRows rows의 모든 행을 스캔하고 마스킹 규칙을 적용해 fn에 넘깁니다
+// orderIndex가 0 이상이면 마스킹 전 원본 순서 컬럼의 마지막 값을 함께 반환합니다
+// 처리량 제한이 있으면 throttleChunkRows 행마다 읽은 만큼 기다립니다 (스트리밍 중에는 서버 쪽 전송도 함께 늦춰짐)
+func (mb *MySQLBackup) readRows(ctx context.Context, rows *sql.Rows, tableName string, columnTypes []*sql.ColumnType, orderIndex int, fn func(values []interface{}) error) (int64, interface{}, error) {
+	columns := make([]string, len(columnTypes))
+	for i, column := range columnTypes {
+		columns[i] = column.Name()
 	}
+	maskRules := mb.masker.ColumnRules(tableName, columns)
 
-	var currentBatch []string
+	var rowCount, pendingRows, pendingBytes int64
+	var lastValue interface{}
 
 	for rows.Next() {
 		values := make([]interface{}, len(columns))
@@ -509,7 +809,7 @@ func (mb *MySQLBackup) processCursorRows(rows *sql.Rows, tableName string, colum
 		}
 
 		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, 0, nil, err
+			return 0, nil, err
 		}
 
 		// 순서 컬럼 값 저장
@@ -517,102 +817,408 @@ func (mb *MySQLBackup) processCursorRows(rows *sql.Rows, tableName string, colum
 			lastValue = values[orderIndex]
 		}
 
-		var valueStrings []string
-		for _, value := range values {
-			if value == nil {
-				valueStrings = append(valueStrings, "NULL")
-			} else {
-				switch v := value.(type) {
-				case []byte:
-					escaped := strings.ReplaceAll(string(v), "'", "\\'")
-					escaped = strings.ReplaceAll(escaped, "\\", "\\\\")
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", escaped))
-				case string:
-					escaped := strings.ReplaceAll(v, "'", "\\'")
-					escaped = strings.ReplaceAll(escaped, "\\", "\\\\")
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", escaped))
-				case time.Time:
-					valueStrings = append(valueStrings, fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05")))
-				default:
-					valueStrings = append(valueStrings, fmt.Sprintf("'%v'", v))
-				}
+		pendingRows++
+		pendingBytes += rowSize(values)
+		if pendingRows >= throttleChunkRows {
+			if err := mb.throttle.Wait(ctx, pendingRows, pendingBytes); err != nil {
+				return 0, nil, err
 			}
+			pendingRows, pendingBytes = 0, 0
 		}
 
-		valueGroup := fmt.Sprintf("(%s)", strings.Join(valueStrings, ", "))
-		currentBatch = append(currentBatch, valueGroup)
-		rowCount++
+		// 마스킹 규칙 적용 (직렬화 직전)
+		mb.masker.Apply(maskRules, values)
 
-		// 배치가 찼으면 INSERT 문 생성
-		if len(currentBatch) >= mb.config.MultiInsert {
-			insertSQL := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s;",
-				tableName,
-				strings.Join(columnNames, ", "),
-				strings.Join(currentBatch, ", "))
-			insertStatements = append(insertStatements, insertSQL)
-			currentBatch = currentBatch[:0]
+		if err := fn(values); err != nil {
+			return 0, nil, err
 		}
+		rowCount++
 	}
 
-	// 남은 배치 처리
-	if len(currentBatch) > 0 {
-		insertSQL := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES %s;",
-			tableName,
-			strings.Join(columnNames, ", "),
-			strings.Join(currentBatch, ", "))
-		insertStatements = append(insertStatements, insertSQL)
+	// 취소나 연결 끊김으로 중단된 경우 잘린 데이터를 성공으로 취급하지 않음
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if err := mb.throttle.Wait(ctx, pendingRows, pendingBytes); err != nil {
+		return 0, nil, err
 	}
 
-	return insertStatements, rowCount, lastValue, nil
+	return rowCount, lastValue, nil
 }
 
-func (mb *MySQLBackup) backupTableWorker(tableName string, index int, resultChan chan<- TableBackupResult, progressChan chan<- string) {
+func (mb *MySQLBackup) backupTableWorker(ctx context.Context, unit backupUnit, index int, resultChan chan<- TableBackupResult) {
 	start := time.Now()
-	progressChan <- fmt.Sprintf("🔄 테이블 '%s' 백업 시작...", tableName)
+	name := unit.Name()
+	resume := mb.checkpoint.Table(name)
+
+	partName := unit.partFileName(index, formatExtensions[mb.config.Format])
+	if resume != nil && resume.Part != "" {
+		partName = resume.Part
+	}
+	partPath := filepath.Join(mb.workDir, partName)
+
+	// 이전 실행에서 이미 완료된 테이블은 다시 백업하지 않음
+	if resume != nil && resume.Status == TableStatusCompleted && partFileIntact(partPath, resume.Bytes) {
+		mb.logger.Info("체크포인트에서 완료된 테이블을 건너뜁니다", "table", name, "rows", resume.Rows)
+		mb.progress.SkipTable(name, resume.Rows)
+		// 이전 실행에서 계산한 체크섬을 푸터에 다시 기록 (체크섬 없이 완료된 테이블은 missing)
+		if resume.Checksum != nil {
+			mb.checksums.Restore(unit.Table, unit.Partition, resume.Checksum)
+		} else {
+			mb.checksums.MarkMissing(unit.Table)
+		}
+		resultChan <- TableBackupResult{
+			TableName: unit.Table,
+			Partition: unit.Partition,
+			Index:     index,
+			RowCount:  resume.Rows,
+			TempFile:  partPath,
+			Method:    resume.Method,
+			Bytes:     resume.Bytes,
+		}
+		return
+	}
 
-	sql, rowCount, err := mb.BackupTable(tableName)
+	mb.logger.Info("테이블 백업 시작", "table", name)
+
+	rowCount, method, bytes, err := mb.backupTableToPart(ctx, unit, partPath, resume)
 	duration := time.Since(start)
+	mb.progress.FinishTable(name)
+
+	// 취소 때문에 실패한 테이블은 원래 오류 대신 취소로 구분 (메트릭에서 실패로 세지 않음)
+	if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		err = fmt.Errorf("%w: %v", ctx.Err(), err)
+	}
 
 	if err != nil {
-		progressChan <- fmt.Sprintf("❌ 테이블 '%s' 백업 실패 (%.2fs): %v", tableName, duration.Seconds(), err)
+		mb.logger.Error("테이블 백업 실패",
+			"table", name, "method", method, "duration", duration, "error", err)
 	} else {
-		progressChan <- fmt.Sprintf("✓ 테이블 '%s' 백업 완료 (%.2fs, %d행)", tableName, duration.Seconds(), rowCount)
+		mb.logger.Info("테이블 백업 완료",
+			"table", name, "method", method, "rows", rowCount, "bytes", bytes, "duration", duration)
 	}
 
 	resultChan <- TableBackupResult{
-		TableName: tableName,
+		TableName: unit.Table,
+		Partition: unit.Partition,
 		Error:     err,
 		Index:     index,
 		RowCount:  rowCount,
-		TempFile:  sql, // SQL 내용을 TempFile 필드에 임시 저장
+		TempFile:  partPath,
+		Method:    method,
+		Bytes:     bytes,
+		Duration:  duration,
+	}
+}
+
+// backupTableToPart 테이블(또는 파티션)을 파트 파일에 백업하고 체크포인트에 완료를 기록합니다
+// 진행 중이던 커서 테이블은 체크포인트의 위치까지 남기고 그 뒤부터 이어서 기록합니다 (이어 쓸 수 없는 형식은 처음부터)
+func (mb *MySQLBackup) backupTableToPart(ctx context.Context, unit backupUnit, partPath string, resume *TableCheckpoint) (int64, string, int64, error) {
+	var offset int64
+	if resume != nil && resume.LastValue != nil && appendableFormat(mb.config.Format) && partFileAtLeast(partPath, resume.Bytes) {
+		offset = resume.Bytes
+	} else {
+		resume = nil
+		if err := mb.checkpoint.StartTable(unit.Name(), filepath.Base(partPath)); err != nil {
+			return 0, "", 0, err
+		}
+	}
+
+	out, err := openPartWriter(partPath, offset)
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	rowCount, method, err := mb.BackupTable(ctx, unit, out, resume)
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("파트 파일 기록 실패: %v", closeErr)
+	}
+	if err != nil {
+		return 0, method, 0, err
+	}
+
+	if err := mb.checkpoint.CompleteTable(unit.Name(), method, rowCount, out.Size(), mb.checksums.Unit(unit.Table, unit.Partition)); err != nil {
+		return 0, method, 0, err
 	}
+	return rowCount, method, out.Size(), nil
+}
+
+// partFileName 원래 순서와 테이블 이름이 드러나는 파트 파일 이름을 만듭니다
+func partFileName(index int, tableName, ext string) string {
+	safeName := strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, tableName)
+	return fmt.Sprintf("%05d_%s%s", index, safeName, ext)
+}
+
+// partFileIntact 완료된 파트 파일이 체크포인트에 기록된 크기 그대로인지 확인합니다
+func partFileIntact(path string, size int64) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() == size
+}
+
+// partFileAtLeast 파트 파일이 체크포인트 위치까지 온전히 남아있는지 확인합니다
+func partFileAtLeast(path string, size int64) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() >= size
 }
 
-func (mb *MySQLBackup) BackupDatabase() error {
+// appendPartFile 파트 파일 내용을 최종 백업 파일에 이어 붙입니다
+func appendPartFile(w io.Writer, path string) error {
+	part, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	_, err = io.Copy(w, part)
+	return err
+}
+
+func (mb *MySQLBackup) BackupDatabase(ctx context.Context) (err error) {
 	start := time.Now()
+	completedCount := 0
+	failedCount := 0
+	totalRows := int64(0)
+
+	// 성공/실패와 관계없이 실행 결과를 메트릭에 기록
+	defer func() {
+		mb.metrics.RecordRun(time.Since(start), totalRows, completedCount, failedCount, err)
+	}()
 
-	// 출력 디렉토리 생성
-	if err := os.MkdirAll(mb.config.OutputDir, 0755); err != nil {
+	if mb.config.Stdout {
+		// 이미 내보낸 출력은 되돌릴 수 없으므로 하나의 SQL 스트림만, 처음부터 끝까지 한 번에
+		if isDirectoryFormat(mb.config.Format) {
+			return fmt.Errorf("stdout 출력(-o -)은 sql 형식만 지원합니다")
+		}
+		if mb.config.Resume {
+			return fmt.Errorf("stdout 출력(-o -)에서는 --resume을 지원하지 않습니다")
+		}
+	} else if err := os.MkdirAll(mb.config.OutputDir, 0755); err != nil {
+		// 출력 디렉토리 생성
 		return fmt.Errorf("출력 디렉토리 생성 실패: %v", err)
 	}
 
-	// 파일명 생성 (타임스탬프 포함)
+	// 공간 값의 축 순서 옵션은 MySQL 8.0.12부터 지원 (MariaDB와 5.7에서 복원되도록 서버에 맞춰 기록, 체크포인트 설정에 포함)
+	var version string
+	if err := mb.db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return fmt.Errorf("서버 버전 조회 실패: %v", err)
+	}
+	mb.geometryAxisOrder = geometryAxisOrderSupported(version)
+
+	// --resume이면 가장 최근 체크포인트를 이어서, 아니면 새 백업 시작
 	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("%s_backup_%s.sql", mb.config.Database, timestamp)
-	filepath := filepath.Join(mb.config.OutputDir, filename)
+	if mb.config.Resume && mb.subsetSpec != nil {
+		// 표본은 실행할 때마다 다시 계산되므로 이전 실행의 파트 파일과 섞이면 참조 무결성이 깨짐
+		return fmt.Errorf("부분 추출 모드에서는 --resume을 지원하지 않습니다")
+	}
+	if mb.config.Resume {
+		checkpointPath, err := FindLatestCheckpoint(mb.config.OutputDir, mb.config.Database)
+		if err != nil {
+			return fmt.Errorf("체크포인트 검색 실패: %v", err)
+		}
+		if checkpointPath == "" {
+			mb.logger.Warn("이어서 할 백업이 없습니다. 새로 백업을 시작합니다.")
+		} else {
+			cp, err := LoadCheckpoint(checkpointPath)
+			if err != nil {
+				return err
+			}
+			if err := cp.CheckSettings(mb.config.Format, mb.outputSettings()); err != nil {
+				return fmt.Errorf("%s: %v", checkpointPath, err)
+			}
+			mb.checkpoint = cp
+			timestamp = cp.Timestamp
+			mb.logger.Info("중단된 백업을 이어서 실행합니다", "checkpoint", checkpointPath, "started_at", cp.StartedAt)
+		}
+	}
+
+	// 파일명 생성 (타임스탬프 포함)
+	// 작업 중에는 임시 이름으로 쓰고, 모든 테이블이 성공했을 때만 최종 이름으로 변경
+	// 디렉토리 형식은 작업 디렉토리 자체를 최종 디렉토리 이름으로 변경
+	baseName := fmt.Sprintf("%s_backup_%s", mb.config.Database, timestamp)
+	outputPath := filepath.Join(mb.config.OutputDir, baseName+".sql")
+	incompletePath := filepath.Join(mb.config.OutputDir, baseName+".incomplete.sql")
+	if isDirectoryFormat(mb.config.Format) {
+		outputPath = filepath.Join(mb.config.OutputDir, baseName)
+		incompletePath = filepath.Join(mb.config.OutputDir, baseName+".incomplete")
+	}
+	if mb.config.Stdout {
+		outputPath, incompletePath = "-", "-"
+	}
+
+	// 테이블별 파트 파일과 체크포인트 준비
+	// stdout 출력은 차례를 기다리는 테이블만 임시 디렉토리에 두고, 이어서 할 수 없으므로 체크포인트를 쓰지 않음
+	mb.workDir = filepath.Join(mb.config.OutputDir, baseName+".parts")
+	if mb.config.Stdout {
+		mb.workDir, err = os.MkdirTemp("", baseName+".parts-")
+		if err != nil {
+			return fmt.Errorf("작업 디렉토리 생성 실패: %v", err)
+		}
+		defer os.RemoveAll(mb.workDir)
+	}
+	if isDirectoryFormat(mb.config.Format) && mb.checkpoint != nil && !fileExists(mb.workDir) && fileExists(incompletePath) {
+		// 불완전한 디렉토리 백업을 이어서 할 때는 성공한 테이블 파일을 다시 작업 디렉토리로 가져옴
+		if err := os.Rename(incompletePath, mb.workDir); err != nil {
+			return fmt.Errorf("불완전한 백업 디렉토리 복원 실패: %v", err)
+		}
+	}
+	if err := os.MkdirAll(mb.workDir, 0755); err != nil {
+		return fmt.Errorf("작업 디렉토리 생성 실패: %v", err)
+	}
+	if mb.checkpoint == nil && !mb.config.Stdout {
+		mb.checkpoint = NewCheckpoint(filepath.Join(mb.config.OutputDir, baseName+".checkpoint.json"), mb.config.Database, timestamp,
+			mb.config.Format, mb.outputSettings())
+		if err := mb.checkpoint.Save(); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		// 완료되지 않은 백업은 작업 디렉토리와 체크포인트를 남겨 이어서 할 수 있게 함
+		if err != nil && mb.checkpoint != nil {
+			mb.logger.Info("--resume 옵션으로 중단된 백업을 이어서 실행할 수 있습니다",
+				"checkpoint", mb.checkpoint.Path(), "work_dir", mb.workDir)
+		}
+		mb.checkpoint = nil
+	}()
 
-	// 백업 파일 생성
-	file, err := os.Create(filepath)
+	// 테이블 목록 조회
+	tables, err := mb.GetTables(ctx)
 	if err != nil {
-		return fmt.Errorf("백업 파일 생성 실패: %v", err)
+		return err
 	}
-	defer file.Close()
 
-	// 버퍼링된 writer 사용 (성능 향상)
-	writer := bufio.NewWriterSize(file, 1024*1024) // 1MB 버퍼
-	defer writer.Flush()
+	// 부모 테이블이 먼저 복원되도록 외래 키 의존 순서로 정렬
+	fks, err := mb.loadForeignKeys(ctx)
+	if err != nil {
+		return err
+	}
+	tables, deferred := sortTablesByDependencies(tables, fks)
+	if len(deferred) > 0 {
+		names := make([]string, len(deferred))
+		for i, fk := range deferred {
+			names[i] = fk.String()
+		}
+		mb.logger.Warn("순환 참조 외래 키는 모든 데이터를 기록한 뒤 ALTER TABLE로 추가합니다",
+			"constraints", strings.Join(names, ", "))
+	}
+	mb.deferredFKs = make(map[string][]ForeignKey)
+	for _, fk := range deferred {
+		mb.deferredFKs[fk.Table] = append(mb.deferredFKs[fk.Table], fk)
+	}
+	defer func() { mb.deferredFKs = nil }()
+
+	// 병렬 백업 단위: 대용량 파티션 테이블은 파티션마다 나눔 (부분 추출은 선택된 행을 테이블 단위로 읽으므로 제외)
+	var partitions map[string][]TablePartition
+	if mb.config.Partitions == "on" && mb.subsetSpec == nil {
+		var partErr error
+		if partitions, partErr = mb.loadPartitions(ctx, ""); partErr != nil {
+			mb.logger.Warn("파티션 정보를 조회하지 못해 테이블 단위로 백업합니다", "error", partErr)
+		}
+	}
+	units := planBackupUnits(tables, partitions)
+	// 파티션마다 같은 테이블을 다시 분석하지 않도록 테이블당 한 번 분석해 단위에 넣음
+	analyzed := make(map[string]*TableInfo)
+	for i := range units {
+		table := units[i].Table
+		if units[i].Partition == "" {
+			continue
+		}
+		if analyzed[table] == nil {
+			info, err := mb.analyzeTableWithPartitions(ctx, table, partitions[table])
+			if err != nil {
+				return fmt.Errorf("테이블 %s 분석 실패: %v", table, err)
+			}
+			analyzed[table] = info
+		}
+		units[i].Info = analyzed[table]
+	}
+	partitionInfo := ""
+	partitionedTables, partitionUnits := 0, 0
+	for _, unit := range units {
+		if unit.Partition != "" {
+			partitionUnits++
+			if unit.Structure {
+				partitionedTables++
+			}
+		}
+	}
+	if partitionUnits > 0 {
+		partitionInfo = fmt.Sprintf("-- 파티션별 병렬 백업: 테이블 %d개를 파티션 %d개로 나눔\n", partitionedTables, partitionUnits)
+		mb.logger.Info("파티션 테이블을 파티션별로 나눠 백업합니다", "tables", partitionedTables, "partitions", partitionUnits)
+	}
+
+	fkChecks := ""
+	if mb.config.ForeignKeyChecks == "off" {
+		fkChecks = "SET FOREIGN_KEY_CHECKS=0;\n"
+	}
+
+	// 레플리카 모드: 지연 확인, SQL 스레드 정지, 소스 위치 기록 (실패해도 끝나면 복제를 다시 시작)
+	replicaInfo := ""
+	if mb.config.Replica == "on" {
+		mb.replica, replicaInfo, err = mb.startReplicaGuard(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			mb.replica.Stop()
+			mb.replica = nil
+		}()
+	}
+
+	// 부분 추출: 표본과 참조되는 행 선택
+	subsetInfo := ""
+	if mb.subsetSpec != nil {
+		mb.subset, err = mb.buildSubset(ctx, mb.subsetSpec, tables, fks)
+		if err != nil {
+			return fmt.Errorf("부분 추출 실패: %v", err)
+		}
+		defer func() { mb.subset = nil }()
+
+		var subsetRows int64
+		for _, count := range mb.subset.RowCounts() {
+			subsetRows += count
+		}
+		subsetInfo = fmt.Sprintf("-- 부분 추출: 루트 %s, 자식 포함 %t, %d행\n",
+			mb.subsetSpec.RootNames(), mb.subsetSpec.IncludeChildren, subsetRows)
+		mb.logger.Info("부분 추출 대상 선택 완료",
+			"roots", mb.subsetSpec.RootNames(), "tables", len(mb.subset.tables), "rows", subsetRows)
+	}
+
+	// 청크 체크섬: 데이터를 읽은 범위마다 서버에서 계산해 푸터에 기록 (부분 추출은 원본과 비교할 수 없어 제외)
+	checksumInfo := ""
+	if mb.config.Checksum == "on" {
+		if mb.subset != nil {
+			mb.logger.Warn("부분 추출 모드에서는 청크 체크섬을 계산하지 않습니다")
+		} else {
+			mb.checksums = NewChecksumSet()
+			defer func() { mb.checksums = nil }()
+			checksumInfo = "-- 청크 체크섬: 파일 끝의 GOBACK-CHECKSUM 줄 (goback check로 비교)\n"
+		}
+	}
+
+	// 계정과 권한 (데이터와 별도로 푸터 앞 또는 users.sql에 기록)
+	accountsSQL, accountsInfo := "", ""
+	if mb.config.Users == "on" {
+		var count int
+		accountsSQL, count, err = mb.dumpAccounts(ctx)
+		if err != nil {
+			return fmt.Errorf("계정 백업 실패: %v", err)
+		}
+		accountsInfo = fmt.Sprintf("-- 계정과 권한: %d개\n", count)
+	}
+
+	// 데이터베이스 정의 (빈 서버에 복원해도 원래 문자셋/콜레이션으로 만들어지도록)
+	createDatabase, err := mb.createDatabaseSQL(ctx)
+	if err != nil {
+		return fmt.Errorf("데이터베이스 정의 조회 실패: %v", err)
+	}
 
 	// 헤더 작성
+	// 데이터는 utf8mb4 연결로 읽었으므로 복원 세션도 utf8mb4 (레거시 문자셋 컬럼은 바이너리 리터럴이라 변환되지 않음)
 	header := fmt.Sprintf(`-- MySQL 데이터베이스 백업 (적응형 지능 최적화)
 -- 데이터베이스: %s
 -- 생성 시간: %s
@@ -620,74 +1226,137 @@ func (mb *MySQLBackup) BackupDatabase() error {
 -- 워커 수: %d
 -- 배치 크기: %d
 -- 멀티 INSERT 크기: %d
-
-SET FOREIGN_KEY_CHECKS=0;
-SET SQL_MODE="NO_AUTO_VALUE_ON_ZERO";
+-- 출력 형식: %s
+-- 실패 처리 정책: %s
+-- 마스킹 규칙: %d개
+-- 테이블 순서: 외래 키 의존 순서 (데이터 기록 후 추가하는 순환 참조 외래 키 %d개)
+%s%s%s%s%s-- 완료 여부는 파일 끝의 GOBACK-STATUS 표시로 확인합니다 (표시가 없으면 잘린 파일)
+
+SET NAMES utf8mb4;
+%s%sSET SQL_MODE="NO_AUTO_VALUE_ON_ZERO";
 SET time_zone = "+00:00";
 
 `, mb.config.Database, time.Now().Format("2006-01-02 15:04:05"),
-		mb.config.Host, mb.config.Port, mb.config.Workers, mb.config.BatchSize, mb.config.MultiInsert)
+		mb.config.Host, mb.config.Port, mb.config.Workers, mb.config.BatchSize, mb.config.MultiInsert, mb.config.Format,
+		mb.config.FailurePolicy, mb.masker.RuleCount(), len(deferred), partitionInfo, replicaInfo, subsetInfo, accountsInfo, checksumInfo, createDatabase, fkChecks)
 
-	if _, err := writer.WriteString(header); err != nil {
-		return fmt.Errorf("헤더 작성 실패: %v", err)
+	// 실제 사용될 워커 수 (백업 단위 수와 설정된 워커 수 중 작은 값)
+	actualWorkers := mb.config.Workers
+	if len(units) < actualWorkers {
+		actualWorkers = len(units)
 	}
 
-	// 테이블 목록 조회
-	tables, err := mb.GetTables()
-	if err != nil {
-		return err
+	mb.logger.Info("병렬 백업 시작",
+		"tables", len(tables), "workers", actualWorkers, "file", outputPath, "failure_policy", mb.config.FailurePolicy)
+
+	// 진행 상황 추적 (TTY면 실시간 표시, 아니면 주기적 로그)
+	if mb.config.Progress != "off" {
+		estimates, err := mb.getTableRowEstimates(ctx)
+		if err != nil {
+			mb.logger.Debug("추정 행 수 조회 실패", "error", err)
+		}
+		if mb.subset != nil {
+			estimates = mb.subset.RowCounts()
+		}
+		// 파티션 단위는 파티션의 추정 행 수로 (테이블 전체 추정치와 겹치지 않게)
+		for _, unit := range units {
+			if unit.Partition == "" || estimates == nil {
+				continue
+			}
+			delete(estimates, unit.Table)
+			for _, partition := range partitions[unit.Table] {
+				if partition.Name == unit.Partition {
+					estimates[unit.Name()] = partition.EstimatedRows
+				}
+			}
+		}
+		mb.progress = NewProgressTracker(estimates, len(units))
+
+		interval := mb.config.ProgressInterval
+		if mb.display != nil {
+			interval = time.Second
+		}
+		progressDone := make(chan struct{})
+		go mb.progress.Run(progressDone, interval, mb.display, mb.logger)
+		defer func() {
+			close(progressDone)
+			mb.progress = nil
+		}()
 	}
 
-	// 실제 사용될 워커 수 (테이블 수와 설정된 워커 수 중 작은 값)
-	actualWorkers := mb.config.Workers
-	if len(tables) < actualWorkers {
-		actualWorkers = len(tables)
+	// stdout 출력은 헤더를 먼저 내보내고 테이블이 순서대로 끝나는 대로 이어서 씀
+	var merger *streamMerger
+	if mb.config.Stdout {
+		merger = newStreamMerger(mb.stdout, units, mb.logger)
+		if err := merger.WriteHeader(header); err != nil {
+			return err
+		}
 	}
 
-	fmt.Printf("📋 총 %d개의 테이블을 %d개 워커로 병렬 백업합니다.\n", len(tables), actualWorkers)
+	// fail-fast 정책에서 첫 실패 시 나머지 테이블을 취소하기 위한 컨텍스트
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+
+	// 서버 부하가 임계값을 넘으면 새 쿼리와 행 읽기를 일시 정지
+	go mb.throttle.RunLoadGuard(runCtx, mb.db, mb.logger)
 
 	// 채널 생성
-	resultChan := make(chan TableBackupResult, len(tables))
-	progressChan := make(chan string, len(tables)*2)
+	resultChan := make(chan TableBackupResult, len(units))
 
 	// 워크그룹 생성
 	var wg sync.WaitGroup
 
-	// 진행상황 출력 고루틴
-	go func() {
-		for msg := range progressChan {
-			fmt.Println(msg)
-		}
-	}()
-
 	// 워커 풀을 사용하여 테이블 백업 (고루틴 수 제한)
 	semaphore := make(chan struct{}, actualWorkers)
 
-	for i, tableName := range tables {
+	for i, unit := range units {
 		wg.Add(1)
-		go func(tableName string, index int) {
+		go func(unit backupUnit, index int) {
 			defer wg.Done()
-			semaphore <- struct{}{} // 워커 슬롯 획득
-			mb.backupTableWorker(tableName, index, resultChan, progressChan)
+			select {
+			case semaphore <- struct{}{}: // 워커 슬롯 획득
+			case <-runCtx.Done():
+				// 취소되면 대기 중인 테이블은 시작하지 않음
+				resultChan <- TableBackupResult{TableName: unit.Table, Partition: unit.Partition, Index: index, Error: runCtx.Err()}
+				return
+			}
+			mb.backupTableWorker(runCtx, unit, index, resultChan)
 			<-semaphore // 워커 슬롯 반환
-		}(tableName, i)
+		}(unit, i)
 	}
 
 	// 모든 워커 완료 대기
 	go func() {
 		wg.Wait()
 		close(resultChan)
-		close(progressChan)
 	}()
 
-	// 결과 수집 (원래 순서 보존)
-	results := make([]TableBackupResult, len(tables))
-	completedCount := 0
-	failedCount := 0
-	totalRows := int64(0)
+	// 결과 수집 (원래 순서 보존, 파티션 단위도 하나씩)
+	results := make([]TableBackupResult, len(units))
+	var firstFailure *TableBackupResult
+	var streamErr error
 
 	for result := range resultChan {
 		results[result.Index] = result
+		if merger != nil && streamErr == nil {
+			// 받는 쪽이 끊기면 더 백업해도 쓸 곳이 없으므로 중단
+			if streamErr = merger.Add(result); streamErr != nil {
+				cancelRun()
+			}
+		}
+		if result.Error != nil && firstFailure == nil && runCtx.Err() == nil {
+			firstFailure = &results[result.Index]
+			if mb.config.FailurePolicy == FailurePolicyFailFast {
+				mb.logger.Error("fail-fast 정책에 따라 백업을 중단합니다", "table", unitName(result.TableName, result.Partition))
+				cancelRun()
+			}
+		}
+	}
+
+	// 파티션 단위 결과를 테이블별로 합쳐 집계 (실패한 파티션이 있는 테이블의 다른 파티션도 실패로 표시됨)
+	tableResults := groupUnitResults(results)
+	for _, result := range tableResults {
+		mb.metrics.RecordTable(result)
 		if result.Error != nil {
 			failedCount++
 		} else {
@@ -696,57 +1365,276 @@ SET time_zone = "+00:00";
 		}
 	}
 
-	fmt.Printf("\n📊 백업 완료 통계:\n")
-	fmt.Printf("   - 성공: %d개\n", completedCount)
-	fmt.Printf("   - 실패: %d개\n", failedCount)
-	fmt.Printf("   - 총 행 수: %d행\n", totalRows)
-	fmt.Printf("   - 총 소요시간: %.2fs\n\n", time.Since(start).Seconds())
+	if streamErr != nil {
+		return streamErr
+	}
+
+	// 취소된 경우 일부 테이블만 담긴 파일을 완성하지 않음
+	if ctx.Err() != nil {
+		return fmt.Errorf("백업이 취소되었습니다: %w", ctx.Err())
+	}
+	if firstFailure != nil && mb.config.FailurePolicy == FailurePolicyFailFast {
+		return fmt.Errorf("테이블 '%s' 백업 실패로 중단했습니다: %v", unitName(firstFailure.TableName, firstFailure.Partition), firstFailure.Error)
+	}
+
+	mb.logger.Info("테이블 백업 통계",
+		"succeeded", completedCount,
+		"failed", failedCount,
+		"rows", totalRows,
+		"duration", time.Since(start))
+
+	finalPath := outputPath
+	if failedCount > 0 {
+		finalPath = incompletePath
+	}
+	checksumLines, err := mb.checksums.FooterLines(tableResults)
+	if err != nil {
+		return fmt.Errorf("체크섬 기록 실패: %v", err)
+	}
+	footer := "\nSET FOREIGN_KEY_CHECKS=1;\n" + checksumLines + completionMarker(tableResults, failedCount)
+	if accountsSQL != "" && isDirectoryFormat(mb.config.Format) {
+		// 디렉토리 형식은 계정을 따로 두어 데이터만 불러올 때는 빼고 실행할 수 있게 함
+		if err := os.WriteFile(filepath.Join(mb.workDir, accountsFileName), []byte(accountsSQL), 0600); err != nil {
+			return fmt.Errorf("계정 파일 작성 실패: %v", err)
+		}
+		accountsSQL = fmt.Sprintf("\n-- 계정과 권한\nSOURCE %s;\n", accountsFileName)
+	}
+	footer = deferredConstraintsSQL(tableResults, deferred) + accountsSQL + footer
+	switch {
+	case merger != nil:
+		err = merger.Close(footer)
+	case isDirectoryFormat(mb.config.Format):
+		err = mb.writeDirectoryDump(ctx, finalPath, header, footer, results)
+	default:
+		err = mb.writeSQLDump(finalPath, header, footer, results)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 모든 테이블이 성공했으면 더 이상 이어서 할 필요가 없으므로 작업 파일 정리
+	// (디렉토리 형식은 작업 디렉토리가 이미 최종 디렉토리로 바뀌어 남아있지 않음)
+	if failedCount == 0 {
+		if err := os.RemoveAll(mb.workDir); err != nil {
+			mb.logger.Warn("작업 디렉토리 삭제 실패", "work_dir", mb.workDir, "error", err)
+		}
+		if err := mb.checkpoint.Remove(); err != nil {
+			mb.logger.Warn("체크포인트 삭제 실패", "checkpoint", mb.checkpoint.Path(), "error", err)
+		}
+	}
+
+	totalDuration := time.Since(start)
+	if failedCount > 0 {
+		mb.logger.Error("일부 테이블이 누락된 불완전한 백업입니다",
+			"file", finalPath,
+			"tables", len(tables),
+			"failed", failedCount,
+			"rows", totalRows,
+			"duration", totalDuration)
+		return fmt.Errorf("%w: %d개 테이블 실패 (%s)", ErrIncompleteBackup, failedCount, finalPath)
+	}
+
+	mb.logger.Info("백업 완료",
+		"file", finalPath,
+		"tables", len(tables),
+		"rows", totalRows,
+		"duration", totalDuration,
+		"rows_per_sec", float64(totalRows)/totalDuration.Seconds())
+
+	return nil
+}
+
+// writeSQLDump 헤더와 성공한 테이블의 파트 파일, 푸터를 순서대로 합쳐 하나의 SQL 파일을 만듭니다
+// 임시 파일에 쓰고 디스크에 완전히 기록된 뒤에만 최종 이름으로 변경합니다
+func (mb *MySQLBackup) writeSQLDump(finalPath, header, footer string, results []TableBackupResult) (err error) {
+	tempPath := finalPath + ".tmp"
+	// 계정을 함께 기록하면 비밀번호 해시가 들어가므로 users.sql처럼 소유자만 읽을 수 있게 만듦
+	perm := os.FileMode(0666)
+	if mb.config.Users == "on" {
+		perm = 0600
+	}
+	file, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("백업 파일 생성 실패: %v", err)
+	}
+	// 실패한 경우 정상 파일처럼 보이지 않도록 임시 파일 삭제
+	defer func() {
+		if err != nil {
+			file.Close()
+			if removeErr := os.Remove(tempPath); removeErr == nil {
+				mb.logger.Warn("미완성 백업 파일을 삭제했습니다", "file", tempPath)
+			}
+		}
+	}()
+	if perm == 0600 {
+		// 이전 실행이 남긴 임시 파일은 권한이 그대로 남으므로 다시 지정
+		if err := file.Chmod(perm); err != nil {
+			return fmt.Errorf("백업 파일 권한 설정 실패: %v", err)
+		}
+	}
+
+	// 버퍼링된 writer 사용 (성능 향상)
+	writer := bufio.NewWriterSize(file, 1024*1024) // 1MB 버퍼
+	if _, err := writer.WriteString(header); err != nil {
+		return fmt.Errorf("헤더 작성 실패: %v", err)
+	}
 
-	// 임시 파일들을 순서대로 합치기
-	fmt.Printf("📄 임시 파일들을 합치는 중...\n")
+	// 파트 파일들을 순서대로 합치기
 	for i, result := range results {
 		if result.Error != nil {
-			log.Printf("⚠️ 테이블 '%s' 백업 실패: %v", result.TableName, result.Error)
+			mb.logger.Warn("실패한 테이블을 백업 파일에서 제외합니다", "table", unitName(result.TableName, result.Partition), "error", result.Error)
 			continue
 		}
 
 		// 최종 파일에 쓰기
-		if _, err := writer.WriteString(result.TempFile + "\n"); err != nil {
+		if err := appendPartFile(writer, result.TempFile); err != nil {
 			return fmt.Errorf("최종 파일 쓰기 실패: %v", err)
 		}
 
 		// 파일 합치기 진행상황 출력
 		if (i+1)%10 == 0 || i == len(results)-1 {
-			fmt.Printf("📄 [%d/%d] 임시 파일 합치기 완료\n", i+1, len(results))
+			mb.logger.Debug("테이블 파일 쓰기 진행", "written", i+1, "total", len(results))
 		}
 	}
 
-	// 푸터 작성
-	footer := "\nSET FOREIGN_KEY_CHECKS=1;\n"
+	// 푸터 작성 (완료 여부 표시 포함)
 	if _, err := writer.WriteString(footer); err != nil {
 		return fmt.Errorf("푸터 작성 실패: %v", err)
 	}
 
-	totalDuration := time.Since(start)
-	fmt.Printf("🎉 백업이 완료되었습니다: %s\n", filepath)
-	fmt.Printf("⚡ 총 처리 시간: %.2fs (평균 %.2fs/테이블, %.0f행/초)\n",
-		totalDuration.Seconds(),
-		totalDuration.Seconds()/float64(len(tables)),
-		float64(totalRows)/totalDuration.Seconds())
+	// 디스크에 완전히 기록된 뒤에만 이름을 변경
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("백업 파일 쓰기 실패: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("백업 파일 동기화 실패: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("백업 파일 닫기 실패: %v", err)
+	}
+	if err := os.Rename(tempPath, finalPath); err != nil {
+		return fmt.Errorf("백업 파일 이름 변경 실패: %v", err)
+	}
+	return nil
+}
+
+// writeDirectoryDump 작업 디렉토리에 불러오기 스크립트(load.sql)를 쓰고 디렉토리를 최종 이름으로 변경합니다
+// 스크립트는 테이블마다 구조 파일을 SOURCE로 실행한 뒤 데이터 파일을 LOAD DATA LOCAL INFILE로 불러옵니다 (컬럼 목록 명시)
+func (mb *MySQLBackup) writeDirectoryDump(ctx context.Context, finalPath, header, footer string, results []TableBackupResult) error {
+	var script strings.Builder
+	script.WriteString(header)
+	script.WriteString("-- 백업 디렉토리 안에서 실행합니다: mysql --local-infile=1 <데이터베이스> < load.sql\n\n")
+
+	// 파티션 단위는 테이블마다 여러 결과가 이어지며, 구조는 첫 단위의 데이터 파일 옆에 있습니다
+	tableColumns := make(map[string][]string)
+	for _, result := range results {
+		name := unitName(result.TableName, result.Partition)
+		if result.Error != nil {
+			mb.logger.Warn("실패한 테이블을 백업 디렉토리에서 제외합니다", "table", name, "error", result.Error)
+			continue
+		}
+		// 데이터 파일과 같은 컬럼 목록 (생성 컬럼 제외, INVISIBLE 컬럼 포함)
+		columns, seen := tableColumns[result.TableName]
+		if !seen {
+			var err error
+			columns, _, err = mb.dumpColumns(ctx, result.TableName)
+			if err != nil {
+				return fmt.Errorf("테이블 '%s' 컬럼 목록 조회 실패: %v", result.TableName, err)
+			}
+			tableColumns[result.TableName] = columns
+		}
+		script.WriteString(fmt.Sprintf("-- 테이블 %s: %d 행\n", name, result.RowCount))
+		if !seen {
+			script.WriteString(fmt.Sprintf("SOURCE %s;\n", filepath.Base(schemaFilePath(result.TempFile))))
+		}
+		for _, dataFile := range dataFiles(mb.config.Format, result.TempFile) {
+			script.WriteString(loadDataSQL(mb.config.Format, filepath.Base(dataFile), result.TableName, columns) + "\n")
+		}
+		script.WriteString("\n")
+	}
+	script.WriteString(footer)
 
+	scriptPath := filepath.Join(mb.workDir, "load.sql")
+	if err := os.WriteFile(scriptPath, []byte(script.String()), 0644); err != nil {
+		return fmt.Errorf("불러오기 스크립트 작성 실패: %v", err)
+	}
+	if err := os.Rename(mb.workDir, finalPath); err != nil {
+		return fmt.Errorf("백업 디렉토리 이름 변경 실패: %v", err)
+	}
 	return nil
 }
 
+// fileExists 파일 또는 디렉토리가 있는지 확인합니다
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// completionMarker 백업 파일 끝에 붙는 완료 여부 표시를 생성합니다
+// 이 표시가 없는 파일은 쓰는 도중 중단된 파일입니다
+func completionMarker(results []TableBackupResult, failedCount int) string {
+	completedAt := time.Now().Format("2006-01-02 15:04:05")
+	if failedCount == 0 {
+		return fmt.Sprintf("-- GOBACK-STATUS: COMPLETE (%s)\n", completedAt)
+	}
+
+	var marker strings.Builder
+	marker.WriteString(fmt.Sprintf("-- GOBACK-STATUS: INCOMPLETE (%s)\n", completedAt))
+	marker.WriteString(fmt.Sprintf("-- 경고: %d개 테이블이 이 백업에 포함되지 않았습니다\n", failedCount))
+	for _, result := range results {
+		if result.Error != nil {
+			marker.WriteString(fmt.Sprintf("-- GOBACK-MISSING-TABLE: %s (%v)\n",
+				result.TableName, strings.ReplaceAll(result.Error.Error(), "\n", " ")))
+		}
+	}
+	return marker.String()
+}
+
+// deferredConstraintsSQL 순환 참조 때문에 CREATE TABLE에서 뺀 외래 키를 추가하는 문장들
+// 참조하는 쪽이나 참조되는 쪽 테이블이 백업에서 빠졌으면 복원이 실패하지 않도록 주석으로만 남깁니다
+func deferredConstraintsSQL(results []TableBackupResult, deferred []ForeignKey) string {
+	if len(deferred) == 0 {
+		return ""
+	}
+
+	failed := make(map[string]bool)
+	for _, result := range results {
+		if result.Error != nil {
+			failed[result.TableName] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\n-- 순환 참조 외래 키 (모든 데이터 기록 후 추가)\n")
+	for _, fk := range deferred {
+		if failed[fk.Table] || failed[fk.RefTable] {
+			b.WriteString("-- 누락된 테이블로 인해 생략: " + fk.AddConstraintSQL() + "\n")
+			continue
+		}
+		b.WriteString(fk.AddConstraintSQL() + "\n")
+	}
+	return b.String()
+}
+
 func (mb *MySQLBackup) Close() {
 	if mb.db != nil {
 		mb.db.Close()
-		fmt.Println("✓ 데이터베이스 연결이 종료되었습니다.")
+		mb.logger.Debug("데이터베이스 연결 종료")
 	}
 }
 
+// 종료 코드
+const (
+	exitCodeFailure     = 1
+	exitCodeIncomplete  = 2   // 일부 테이블이 누락된 백업 (continue 정책)
+	exitCodeDifferent   = 3   // diff/check 하위 명령에서 비교한 두 쪽이 다름
+	exitCodeInterrupted = 130 // SIGINT/SIGTERM으로 중단됨 (128 + SIGINT)
+)
+
 func main() {
-	fmt.Println("🗃️  MySQL 적응형 지능 백업 도구 시작")
-	fmt.Println("========================================")
+	// SIGINT/SIGTERM을 받으면 진행 중인 쿼리를 취소
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// 환경변수에서 설정 읽기 (우선순위: 환경변수 > 기본값)
 	config := LoadConfigFromEnv()
@@ -756,39 +1644,152 @@ func main() {
 		config.Workers = runtime.NumCPU()
 	}
 
-	// 명령행 인수로 설정 덮어쓰기 (우선순위: 명령행 > 환경변수 > 기본값)
+	// 하위 명령은 백업을 실행하지 않고 결과에 맞는 종료 코드로 끝남
 	if len(os.Args) > 1 {
-		config.Database = os.Args[1]
+		switch os.Args[1] {
+		case "diff":
+			os.Exit(runDiff(ctx, config, os.Args[2:]))
+		case "check":
+			os.Exit(runCheck(ctx, config, os.Args[2:]))
+		case "restore-test":
+			os.Exit(runRestoreTest(ctx, config, os.Args[2:]))
+		}
 	}
-	if len(os.Args) > 2 {
-		config.Host = os.Args[2]
+
+	// 명령행 옵션
+	flag.BoolVar(&config.Resume, "resume", config.Resume, "중단된 가장 최근 백업을 체크포인트에서 이어서 실행")
+	output := flag.String("o", "", "백업 출력 디렉토리 (BACKUP_OUTPUT_DIR 대신), -이면 덤프 전체를 stdout으로 출력")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "사용법: %s [옵션] [데이터베이스명] [호스트] [사용자명]\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s diff [-alter] <기준> <대상>   (스키마 비교)\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s check <백업> [live | live:<데이터베이스>]   (청크 체크섬 비교)\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s restore-test [-database 이름] [-keep] [-report 파일] <백업>   (복원 테스트)\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
 	}
-	if len(os.Args) > 3 {
-		config.Username = os.Args[3]
+	flag.Parse()
+	if *output == "-" {
+		config.Stdout = true
+	} else if *output != "" {
+		config.OutputDir = *output
 	}
 
-	fmt.Printf("🔧 설정 정보:\n")
-	fmt.Printf("   - 호스트: %s:%s\n", config.Host, config.Port)
-	fmt.Printf("   - 사용자: %s\n", config.Username)
-	fmt.Printf("   - 데이터베이스: %s\n", config.Database)
-	fmt.Printf("   - 출력 경로: %s\n", config.OutputDir)
-	fmt.Printf("   - 병렬 워커 수: %d\n", config.Workers)
-	fmt.Printf("   - 배치 크기: %d\n", config.BatchSize)
-	fmt.Printf("   - 멀티 INSERT 크기: %d\n", config.MultiInsert)
-	fmt.Println()
+	// 명령행 인수로 설정 덮어쓰기 (우선순위: 명령행 > 환경변수 > 기본값)
+	args := flag.Args()
+	if len(args) > 0 {
+		config.Database = args[0]
+	}
+	if len(args) > 1 {
+		config.Host = args[1]
+	}
+	if len(args) > 2 {
+		config.Username = args[2]
+	}
+
+	slog.Info("MySQL 백업 도구 시작",
+		"host", config.Host,
+		"port", config.Port,
+		"user", config.Username,
+		"database", config.Database,
+		"output_dir", config.OutputDir,
+		"workers", config.Workers,
+		"batch_size", config.BatchSize,
+		"multi_insert", config.MultiInsert)
+
+	// 진행 상황 표시 방식 결정: TTY에서는 로그가 진행 블록을 거쳐 출력되도록 로거를 교체
+	var display *ProgressDisplay
+	if config.Progress == "tty" || (config.Progress == "auto" && config.LogFormat != "json" && isTerminal(os.Stderr)) {
+		display = NewProgressDisplay(os.Stderr)
+		slog.SetDefault(NewLogger(display, config.LogFormat, config.LogLevel))
+	}
 
 	backup := NewMySQLBackup(config)
+	backup.display = display
+
+	// 마스킹 규칙 로드 (설정된 경우)
+	if config.MaskingRules != "" {
+		masker, err := LoadMasker(config.MaskingRules, config.MaskingSeed)
+		if err != nil {
+			slog.Error("마스킹 규칙 로드 실패", "error", err)
+			os.Exit(exitCodeFailure)
+		}
+		backup.masker = masker
+		slog.Info("컬럼 마스킹을 적용합니다", "file", config.MaskingRules, "rules", masker.RuleCount())
+	}
+
+	// 부분 추출 설정 로드 (설정된 경우)
+	if config.SubsetFile != "" {
+		spec, err := LoadSubsetSpec(config.SubsetFile)
+		if err != nil {
+			slog.Error("부분 추출 설정 로드 실패", "error", err)
+			os.Exit(exitCodeFailure)
+		}
+		backup.subsetSpec = spec
+		slog.Info("부분 추출 모드로 백업합니다", "file", config.SubsetFile, "roots", spec.RootNames())
+	}
 
 	// 데이터베이스 연결
-	if err := backup.Connect(); err != nil {
-		log.Fatal(err)
+	if err := backup.Connect(ctx); err != nil {
+		slog.Error("데이터베이스 연결 실패", "error", err)
+		os.Exit(exitCode(err))
 	}
 	defer backup.Close()
 
+	// 메트릭 HTTP 엔드포인트 (설정된 경우)
+	if config.MetricsAddr != "" {
+		StartMetricsServer(config.MetricsAddr, backup.metrics)
+		slog.Info("메트릭 엔드포인트 시작", "addr", config.MetricsAddr, "path", "/metrics")
+	}
+
+	// 데몬 모드: 주기적으로 백업 실행
+	if config.Interval > 0 && config.Stdout {
+		slog.Error("stdout 출력(-o -)은 데몬 모드(BACKUP_INTERVAL)와 함께 쓸 수 없습니다")
+		os.Exit(exitCodeFailure)
+	}
+	if config.Interval > 0 {
+		slog.Info("데몬 모드로 주기적 백업을 실행합니다", "interval", config.Interval)
+		for {
+			if err := backup.BackupDatabase(ctx); err != nil {
+				slog.Error("백업 실패", "error", err)
+			}
+			writeMetricsFile(backup)
+
+			select {
+			case <-ctx.Done():
+				slog.Warn("종료 신호를 받아 데몬을 종료합니다")
+				backup.Close()
+				os.Exit(exitCodeInterrupted)
+			case <-time.After(config.Interval):
+			}
+		}
+	}
+
 	// 백업 실행
-	if err := backup.BackupDatabase(); err != nil {
-		log.Fatal(err)
+	err := backup.BackupDatabase(ctx)
+	writeMetricsFile(backup)
+	if err != nil {
+		slog.Error("백업 실패", "error", err)
+		backup.Close()
+		os.Exit(exitCode(err))
 	}
+}
 
-	fmt.Println("✨ 모든 작업이 완료되었습니다!")
+// exitCode 오류 종류에 맞는 종료 코드를 반환합니다
+func exitCode(err error) int {
+	if errors.Is(err, context.Canceled) {
+		return exitCodeInterrupted
+	}
+	if errors.Is(err, ErrIncompleteBackup) {
+		return exitCodeIncomplete
+	}
+	return exitCodeFailure
+}
+
+// writeMetricsFile 메트릭 파일 경로가 설정된 경우 textfile을 갱신합니다
+func writeMetricsFile(backup *MySQLBackup) {
+	if backup.config.MetricsFile == "" {
+		return
+	}
+	if err := backup.metrics.WriteTextfile(backup.config.MetricsFile); err != nil {
+		slog.Warn("메트릭 파일 갱신 실패", "file", backup.config.MetricsFile, "error", err)
+	}
 }
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 마스킹 규칙 종류
+const (
+	MaskHash    = "hash"    // 시드 기반 HMAC-SHA256 16진수 (length로 길이 제한)
+	MaskNull    = "null"    // NULL로 치환
+	MaskFixed   = "fixed"   // 고정값으로 치환
+	MaskFake    = "fake"    // 그럴듯한 가짜 값으로 일관되게 치환 (kind: email, name, first_name, last_name, phone, text)
+	MaskPartial = "partial" // 앞 keep_start, 뒤 keep_end 글자만 남기고 가림
+	MaskDigits  = "digits"  // 숫자만 일관되게 바꾸고 나머지 형식은 유지 (전화번호, 카드번호 등)
+)
+
+// MaskRule 컬럼 하나에 적용할 마스킹 규칙
+type MaskRule struct {
+	Type      string `json:"type"`
+	Kind      string `json:"kind,omitempty"`       // fake 종류
+	Value     string `json:"value,omitempty"`      // fixed 값
+	Length    int    `json:"length,omitempty"`     // hash 결과 길이 (0이면 64자 전체)
+	KeepStart int    `json:"keep_start,omitempty"` // partial: 앞에서 남길 글자 수
+	KeepEnd   int    `json:"keep_end,omitempty"`   // partial: 뒤에서 남길 글자 수
+	MaskChar  string `json:"mask_char,omitempty"`  // partial: 가림 문자 (기본 *)
+}
+
+// maskingFile 마스킹 규칙 파일 형식
+// rules의 키는 "테이블.컬럼"이며, 테이블에 *를 쓰면 모든 테이블의 해당 컬럼에 적용됩니다
+type maskingFile struct {
+	Seed  string               `json:"seed"`
+	Rules map[string]*MaskRule `json:"rules"`
+}
+
+// Masker 테이블/컬럼별 마스킹 규칙을 행 값에 적용합니다
+// 같은 시드와 같은 원본 값은 테이블과 컬럼에 관계없이 같은 결과가 되므로 테이블 간 참조 관계가 유지됩니다
+type Masker struct {
+	key   []byte
+	rules map[string]map[string]*MaskRule // 테이블 → 컬럼 → 규칙 ("*"는 모든 테이블)
+}
+
+// LoadMasker 마스킹 규칙 파일을 읽습니다. seed가 비어있지 않으면 파일의 시드 대신 사용합니다
+func LoadMasker(path, seed string) (*Masker, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("마스킹 규칙 파일 읽기 실패: %v", err)
+	}
+
+	var file maskingFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("마스킹 규칙 파일 해석 실패: %v", err)
+	}
+	if seed == "" {
+		seed = file.Seed
+	}
+	if seed == "" {
+		return nil, fmt.Errorf("마스킹 시드가 설정되지 않았습니다 (규칙 파일의 seed 또는 BACKUP_MASKING_SEED)")
+	}
+
+	masker := &Masker{
+		key:   []byte(seed),
+		rules: make(map[string]map[string]*MaskRule),
+	}
+	for target, rule := range file.Rules {
+		table, column, ok := strings.Cut(target, ".")
+		if !ok || table == "" || column == "" {
+			return nil, fmt.Errorf("마스킹 대상 형식이 잘못되었습니다 (테이블.컬럼): %s", target)
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("마스킹 규칙 '%s': %v", target, err)
+		}
+		if masker.rules[table] == nil {
+			masker.rules[table] = make(map[string]*MaskRule)
+		}
+		masker.rules[table][column] = rule
+	}
+
+	return masker, nil
+}
+
+func (r *MaskRule) validate() error {
+	switch r.Type {
+	case MaskHash, MaskNull, MaskFixed, MaskDigits:
+	case MaskPartial:
+		if r.KeepStart < 0 || r.KeepEnd < 0 {
+			return fmt.Errorf("keep_start/keep_end는 0 이상이어야 합니다")
+		}
+	case MaskFake:
+		switch r.Kind {
+		case "email", "name", "first_name", "last_name", "phone", "text":
+		default:
+			return fmt.Errorf("알 수 없는 fake 종류: %q", r.Kind)
+		}
+	default:
+		return fmt.Errorf("알 수 없는 마스킹 종류: %q", r.Type)
+	}
+	return nil
+}
+
+// RuleCount 등록된 규칙 수
+func (m *Masker) RuleCount() int {
+	if m == nil {
+		return 0
+	}
+	count := 0
+	for _, columns := range m.rules {
+		count += len(columns)
+	}
+	return count
+}
+
+// Fingerprint 시드와 규칙으로 만든 해시 (체크포인트에서 이전 실행과 같은 마스킹인지 비교, 마스킹하지 않으면 빈 문자열)
+func (m *Masker) Fingerprint() string {
+	if m == nil {
+		return ""
+	}
+	rules, _ := json.Marshal(m.rules) // 맵 키는 정렬되어 인코딩됨
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write(rules)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ColumnRules 결과 컬럼 순서에 맞춘 규칙 목록을 반환합니다 (적용할 규칙이 없으면 nil)
+// 행마다 맵을 조회하지 않도록 테이블당 한 번 계산합니다
+func (m *Masker) ColumnRules(tableName string, columns []string) []*MaskRule {
+	if m == nil {
+		return nil
+	}
+
+	var rules []*MaskRule
+	for i, column := range columns {
+		rule := m.rules[tableName][column]
+		if rule == nil {
+			rule = m.rules["*"][column]
+		}
+		if rule == nil {
+			continue
+		}
+		if rules == nil {
+			rules = make([]*MaskRule, len(columns))
+		}
+		rules[i] = rule
+	}
+	return rules
+}
+
+// Apply 행 값에 규칙을 적용합니다 (rules가 nil이면 아무 것도 하지 않음)
+func (m *Masker) Apply(rules []*MaskRule, values []interface{}) {
+	for i, rule := range rules {
+		if rule == nil || values[i] == nil {
+			continue
+		}
+		values[i] = m.mask(rule, values[i])
+	}
+}
+
+func (m *Masker) mask(rule *MaskRule, value interface{}) interface{} {
+	original := maskInput(value)
+
+	switch rule.Type {
+	case MaskNull:
+		return nil
+	case MaskFixed:
+		return rule.Value
+	case MaskHash:
+		hashed := hex.EncodeToString(m.digest(original))
+		if rule.Length > 0 && rule.Length < len(hashed) {
+			hashed = hashed[:rule.Length]
+		}
+		return hashed
+	case MaskPartial:
+		return partialMask(original, rule)
+	case MaskDigits:
+		return m.maskDigits(original)
+	case MaskFake:
+		return m.fake(rule.Kind, original)
+	}
+	return value
+}
+
+// maskInput 규칙 적용을 위해 값을 문자열로 바꿉니다
+func maskInput(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// digest 시드를 키로 한 원본 값의 HMAC-SHA256
+func (m *Masker) digest(value string) []byte {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func partialMask(value string, rule *MaskRule) string {
+	maskChar := rule.MaskChar
+	if maskChar == "" {
+		maskChar = "*"
+	}
+
+	runes := []rune(value)
+	if rule.KeepStart+rule.KeepEnd >= len(runes) {
+		return strings.Repeat(maskChar, len(runes))
+	}
+
+	var b strings.Builder
+	b.WriteString(string(runes[:rule.KeepStart]))
+	b.WriteString(strings.Repeat(maskChar, len(runes)-rule.KeepStart-rule.KeepEnd))
+	b.WriteString(string(runes[len(runes)-rule.KeepEnd:]))
+	return b.String()
+}
+
+// maskDigits 숫자 자리만 HMAC 스트림에서 뽑은 숫자로 바꾸고 구분자 등 나머지 문자는 유지합니다
+func (m *Masker) maskDigits(value string) string {
+	stream := m.digest(value)
+	var b strings.Builder
+	b.Grow(len(value))
+
+	pos := 0
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			b.WriteRune(r)
+			continue
+		}
+		// 32바이트를 다 쓰면 블록 번호를 붙여 스트림 확장
+		if pos == len(stream) {
+			stream = m.digest(fmt.Sprintf("%s#%d", value, pos))
+			pos = 0
+		}
+		b.WriteByte('0' + stream[pos]%10)
+		pos++
+	}
+	return b.String()
+}
+
+var (
+	fakeFirstNames = []string{"민준", "서연", "도윤", "하은", "시우", "지유", "주원", "서윤", "하준", "지아",
+		"James", "Olivia", "Liam", "Emma", "Noah", "Ava", "Lucas", "Mia", "Ethan", "Sophia"}
+	fakeLastNames = []string{"김", "이", "박", "최", "정", "강", "조", "윤", "장", "임",
+		"Smith", "Johnson", "Brown", "Jones", "Garcia", "Miller", "Davis", "Wilson", "Moore", "Taylor"}
+	fakeWords = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit",
+		"sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua"}
+)
+
+// fake 원본 값에서 결정적으로 만든 가짜 값
+func (m *Masker) fake(kind, value string) string {
+	sum := m.digest(value)
+	pick := func(list []string, offset int) string {
+		return list[binary.BigEndian.Uint32(sum[offset:offset+4])%uint32(len(list))]
+	}
+
+	switch kind {
+	case "email":
+		return fmt.Sprintf("user_%s@example.com", hex.EncodeToString(sum[:6]))
+	case "first_name":
+		return pick(fakeFirstNames, 0)
+	case "last_name":
+		return pick(fakeLastNames, 4)
+	case "name":
+		return pick(fakeLastNames, 4) + " " + pick(fakeFirstNames, 0)
+	case "phone":
+		// 원본 형식이 있으면 유지, 없으면 010-XXXX-XXXX
+		if strings.ContainsAny(value, "0123456789") {
+			return m.maskDigits(value)
+		}
+		return "010-" + m.maskDigits("0000-0000#" + value)[:9]
+	case "text":
+		// 원본과 비슷한 길이의 무의미한 문장
+		length := utf8.RuneCountInString(value)
+		var words []string
+		total := 0
+		for i := 0; total < length; i++ {
+			word := pick(fakeWords, (i*4)%(len(sum)-4))
+			words = append(words, word)
+			total += len(word) + 1
+		}
+		text := strings.Join(words, " ")
+		if len(text) > length {
+			text = text[:length]
+		}
+		return text
+	}
+	return value
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func testMasker(seed string) *Masker {
+	return &Masker{key: []byte(seed), rules: make(map[string]map[string]*MaskRule)}
+}
+
+func TestMaskDeterministicWithSeed(t *testing.T) {
+	rules := []*MaskRule{
+		{Type: MaskHash},
+		{Type: MaskHash, Length: 12},
+		{Type: MaskDigits},
+		{Type: MaskFake, Kind: "email"},
+		{Type: MaskFake, Kind: "name"},
+		{Type: MaskFake, Kind: "phone"},
+		{Type: MaskFake, Kind: "text"},
+	}
+	a, b, other := testMasker("seed-1"), testMasker("seed-1"), testMasker("seed-2")
+	for _, rule := range rules {
+		value := "010-1234-5678 hello@example.com"
+		first, second := a.mask(rule, value), b.mask(rule, []byte(value))
+		if first != second {
+			t.Errorf("%s/%s: same seed gave %q and %q", rule.Type, rule.Kind, first, second)
+		}
+		if first == value {
+			t.Errorf("%s/%s: value was not masked", rule.Type, rule.Kind)
+		}
+		if rule.Kind != "name" && other.mask(rule, value) == first {
+			t.Errorf("%s/%s: different seeds gave the same result %q", rule.Type, rule.Kind, first)
+		}
+	}
+	if got := a.mask(&MaskRule{Type: MaskHash, Length: 12}, "x"); len(got.(string)) != 12 {
+		t.Errorf("hash length = %d, want 12", len(got.(string)))
+	}
+}
+
+func TestPartialMask(t *testing.T) {
+	tests := []struct {
+		value string
+		rule  MaskRule
+		want  string
+	}{
+		{"hello@example.com", MaskRule{KeepStart: 2, KeepEnd: 4}, "he***********.com"},
+		{"홍길동", MaskRule{KeepStart: 1}, "홍**"},
+		{"abcd", MaskRule{KeepStart: 2, KeepEnd: 2}, "****"},
+		{"ab", MaskRule{KeepStart: 3, KeepEnd: 3}, "**"},
+		{"a", MaskRule{KeepEnd: 1, MaskChar: "#"}, "#"},
+		{"", MaskRule{KeepStart: 1}, ""},
+		{"12345", MaskRule{KeepEnd: 2, MaskChar: "x"}, "xxx45"},
+	}
+	for _, tt := range tests {
+		if got := partialMask(tt.value, &tt.rule); got != tt.want {
+			t.Errorf("partialMask(%q, %+v) = %q, want %q", tt.value, tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestMaskDigitsKeepsFormat(t *testing.T) {
+	m := testMasker("seed")
+	for _, value := range []string{"", "-", "7", "010-1234-5678", "4111 1111 1111 1111", strings.Repeat("9", 100)} {
+		got := m.maskDigits(value)
+		if len(got) != len(value) {
+			t.Fatalf("maskDigits(%q) = %q, length changed", value, got)
+		}
+		for i := range value {
+			if unicode.IsDigit(rune(value[i])) != unicode.IsDigit(rune(got[i])) {
+				t.Errorf("maskDigits(%q) = %q, format changed at %d", value, got, i)
+			}
+			if !unicode.IsDigit(rune(value[i])) && value[i] != got[i] {
+				t.Errorf("maskDigits(%q) = %q, separator changed at %d", value, got, i)
+			}
+		}
+	}
+}
+
+func TestFakePhoneWithoutDigits(t *testing.T) {
+	got := testMasker("seed").fake("phone", "unknown")
+	if len(got) != 13 || got[:4] != "010-" || got[8] != '-' {
+		t.Errorf("fake phone = %q, want 010-XXXX-XXXX", got)
+	}
+}
+
+func TestMaskerApplyAndColumnRules(t *testing.T) {
+	m := testMasker("seed")
+	m.rules["users"] = map[string]*MaskRule{"email": {Type: MaskNull}}
+	m.rules["*"] = map[string]*MaskRule{"phone": {Type: MaskFixed, Value: "000"}, "email": {Type: MaskHash}}
+
+	rules := m.ColumnRules("users", []string{"id", "email", "phone"})
+	values := []interface{}{int64(1), []byte("a@b.c"), nil}
+	m.Apply(rules, values)
+	if values[0] != int64(1) || values[1] != nil || values[2] != nil {
+		t.Errorf("users values = %#v, want id kept, email NULL, NULL phone kept", values)
+	}
+
+	values = []interface{}{"010"}
+	m.Apply(m.ColumnRules("orders", []string{"phone"}), values)
+	if values[0] != "000" {
+		t.Errorf("wildcard rule gave %#v, want fixed value", values[0])
+	}
+	if m.ColumnRules("orders", []string{"id"}) != nil {
+		t.Error("ColumnRules without matching rules != nil")
+	}
+
+	var nilMasker *Masker
+	if nilMasker.ColumnRules("users", []string{"email"}) != nil || nilMasker.RuleCount() != 0 {
+		t.Error("nil Masker should have no rules")
+	}
+}
+
+func TestLoadMasker(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	valid := write("valid.json", `{"seed": "file-seed", "rules": {"users.email": {"type": "hash"}, "*.phone": {"type": "digits"}}}`)
+	m, err := LoadMasker(valid, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.RuleCount() != 2 || string(m.key) != "file-seed" {
+		t.Errorf("RuleCount = %d, key = %q", m.RuleCount(), m.key)
+	}
+	if m, err = LoadMasker(valid, "env-seed"); err != nil || string(m.key) != "env-seed" {
+		t.Errorf("seed override: key = %q, err = %v", m.key, err)
+	}
+
+	invalid := map[string]string{
+		"noseed.json":    `{"rules": {"users.email": {"type": "hash"}}}`,
+		"target.json":    `{"seed": "s", "rules": {"email": {"type": "hash"}}}`,
+		"type.json":      `{"seed": "s", "rules": {"users.email": {"type": "scramble"}}}`,
+		"fake.json":      `{"seed": "s", "rules": {"users.email": {"type": "fake", "kind": "ssn"}}}`,
+		"partial.json":   `{"seed": "s", "rules": {"users.email": {"type": "partial", "keep_start": -1}}}`,
+		"malformed.json": `{"seed": `,
+	}
+	for name, content := range invalid {
+		if _, err := LoadMasker(write(name, content), ""); err == nil {
+			t.Errorf("LoadMasker(%s) succeeded, want error", name)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tableMetric 테이블 하나의 마지막 백업 결과와 누적 실패/취소 횟수
+type tableMetric struct {
+	Method        string
+	Duration      time.Duration
+	Rows          int64
+	Bytes         int64
+	LastSuccess   time.Time
+	Failures      int64
+	Cancellations int64
+}
+
+// BackupMetrics 백업 실행 통계를 Prometheus 텍스트 포맷으로 노출합니다
+// node_exporter textfile 수집기용 파일 쓰기와 HTTP /metrics 엔드포인트를 모두 지원합니다
+type BackupMetrics struct {
+	mu       sync.Mutex
+	database string
+	tables   map[string]*tableMetric
+
+	runs          int64
+	runFailures   int64
+	lastSuccess   time.Time
+	lastDuration  time.Duration
+	lastRows      int64
+	lastSucceeded int
+	lastFailed    int
+}
+
+func NewBackupMetrics(database string) *BackupMetrics {
+	return &BackupMetrics{
+		database: database,
+		tables:   make(map[string]*tableMetric),
+	}
+}
+
+// RecordTable 테이블 백업 결과를 기록합니다
+func (m *BackupMetrics) RecordTable(result TableBackupResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tm, ok := m.tables[result.TableName]
+	if !ok {
+		tm = &tableMetric{}
+		m.tables[result.TableName] = tm
+	}
+
+	if result.Method != "" {
+		tm.Method = result.Method
+	}
+	tm.Duration = result.Duration
+
+	// 다른 테이블의 실패(fail-fast)나 중단 신호로 취소된 테이블은 실패와 따로 셈 (오류 하나가 여러 테이블 실패로 보이지 않도록)
+	if errors.Is(result.Error, context.Canceled) {
+		tm.Cancellations++
+		return
+	}
+	if result.Error != nil {
+		tm.Failures++
+		return
+	}
+
+	tm.Rows = result.RowCount
+	tm.Bytes = result.Bytes
+	tm.LastSuccess = time.Now()
+}
+
+// RecordRun 전체 백업 실행 결과를 기록합니다
+// 실패한 테이블이 하나라도 있으면 실패한 실행으로 간주합니다
+func (m *BackupMetrics) RecordRun(duration time.Duration, totalRows int64, succeeded, failed int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.runs++
+	m.lastDuration = duration
+	m.lastRows = totalRows
+	m.lastSucceeded = succeeded
+	m.lastFailed = failed
+
+	if err != nil || failed > 0 {
+		m.runFailures++
+		return
+	}
+	m.lastSuccess = time.Now()
+}
+
+// WriteTo Prometheus 텍스트 포맷(0.0.4)으로 메트릭을 출력합니다
+func (m *BackupMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var buf bytes.Buffer
+	db := fmt.Sprintf(`database="%s"`, escapeLabelValue(m.database))
+
+	writeMetricHeader(&buf, "goback_backup_runs_total", "counter", "백업 실행 횟수")
+	fmt.Fprintf(&buf, "goback_backup_runs_total{%s} %d\n", db, m.runs)
+
+	writeMetricHeader(&buf, "goback_backup_failures_total", "counter", "실패했거나 일부 테이블이 누락된 백업 실행 횟수")
+	fmt.Fprintf(&buf, "goback_backup_failures_total{%s} %d\n", db, m.runFailures)
+
+	writeMetricHeader(&buf, "goback_backup_last_success_timestamp_seconds", "gauge", "마지막 성공한 백업의 완료 시각 (unix time)")
+	fmt.Fprintf(&buf, "goback_backup_last_success_timestamp_seconds{%s} %s\n", db, formatTimestamp(m.lastSuccess))
+
+	writeMetricHeader(&buf, "goback_backup_last_duration_seconds", "gauge", "마지막 백업 실행의 소요 시간")
+	fmt.Fprintf(&buf, "goback_backup_last_duration_seconds{%s} %g\n", db, m.lastDuration.Seconds())
+
+	writeMetricHeader(&buf, "goback_backup_last_rows", "gauge", "마지막 백업 실행에서 백업된 총 행 수")
+	fmt.Fprintf(&buf, "goback_backup_last_rows{%s} %d\n", db, m.lastRows)
+
+	writeMetricHeader(&buf, "goback_backup_last_rows_per_second", "gauge", "마지막 백업 실행의 평균 처리량")
+	rowsPerSec := 0.0
+	if m.lastDuration > 0 {
+		rowsPerSec = float64(m.lastRows) / m.lastDuration.Seconds()
+	}
+	fmt.Fprintf(&buf, "goback_backup_last_rows_per_second{%s} %g\n", db, rowsPerSec)
+
+	writeMetricHeader(&buf, "goback_backup_last_tables", "gauge", "마지막 백업 실행의 상태별 테이블 수")
+	fmt.Fprintf(&buf, "goback_backup_last_tables{%s,status=\"success\"} %d\n", db, m.lastSucceeded)
+	fmt.Fprintf(&buf, "goback_backup_last_tables{%s,status=\"failed\"} %d\n", db, m.lastFailed)
+
+	names := make([]string, 0, len(m.tables))
+	for name := range m.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tableLabels := func(name string) string {
+		return fmt.Sprintf(`%s,table="%s"`, db, escapeLabelValue(name))
+	}
+	methodLabels := func(name string, tm *tableMetric) string {
+		method := tm.Method
+		if method == "" {
+			method = "unknown"
+		}
+		return fmt.Sprintf(`%s,method="%s"`, tableLabels(name), escapeLabelValue(method))
+	}
+
+	writeMetricHeader(&buf, "goback_table_backup_duration_seconds", "gauge", "테이블별 마지막 백업 소요 시간")
+	for _, name := range names {
+		tm := m.tables[name]
+		fmt.Fprintf(&buf, "goback_table_backup_duration_seconds{%s} %g\n", methodLabels(name, tm), tm.Duration.Seconds())
+	}
+
+	writeMetricHeader(&buf, "goback_table_backup_rows", "gauge", "테이블별 마지막 성공한 백업의 행 수")
+	for _, name := range names {
+		tm := m.tables[name]
+		fmt.Fprintf(&buf, "goback_table_backup_rows{%s} %d\n", methodLabels(name, tm), tm.Rows)
+	}
+
+	writeMetricHeader(&buf, "goback_table_backup_bytes", "gauge", "테이블별 마지막 성공한 백업의 출력 크기")
+	for _, name := range names {
+		tm := m.tables[name]
+		fmt.Fprintf(&buf, "goback_table_backup_bytes{%s} %d\n", methodLabels(name, tm), tm.Bytes)
+	}
+
+	writeMetricHeader(&buf, "goback_table_backup_last_success_timestamp_seconds", "gauge", "테이블별 마지막 성공한 백업의 완료 시각 (unix time)")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "goback_table_backup_last_success_timestamp_seconds{%s} %s\n", tableLabels(name), formatTimestamp(m.tables[name].LastSuccess))
+	}
+
+	writeMetricHeader(&buf, "goback_table_backup_failures_total", "counter", "테이블별 백업 실패 횟수")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "goback_table_backup_failures_total{%s} %d\n", tableLabels(name), m.tables[name].Failures)
+	}
+
+	writeMetricHeader(&buf, "goback_table_backup_cancellations_total", "counter", "다른 테이블의 실패나 중단 신호로 취소된 테이블별 횟수")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "goback_table_backup_cancellations_total{%s} %d\n", tableLabels(name), m.tables[name].Cancellations)
+	}
+
+	return buf.WriteTo(w)
+}
+
+// WriteTextfile node_exporter textfile 수집기용 파일을 원자적으로 갱신합니다
+// 수집기가 쓰는 도중의 파일을 읽지 않도록 임시 파일에 쓴 뒤 이름을 바꿉니다
+func (m *BackupMetrics) WriteTextfile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".goback_metrics_*.prom.tmp")
+	if err != nil {
+		return fmt.Errorf("메트릭 임시 파일 생성 실패: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := m.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("메트릭 파일 쓰기 실패: %v", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("메트릭 파일 권한 설정 실패: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("메트릭 파일 닫기 실패: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("메트릭 파일 교체 실패: %v", err)
+	}
+	return nil
+}
+
+// ServeHTTP /metrics 엔드포인트 핸들러
+func (m *BackupMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// StartMetricsServer 주어진 주소에서 /metrics 엔드포인트를 제공하는 HTTP 서버를 시작합니다
+func StartMetricsServer(addr string, metrics *BackupMetrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("메트릭 서버 실행 실패", "addr", addr, "error", err)
+		}
+	}()
+
+	return server
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name, metricType, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, metricType)
+}
+
+// formatTimestamp 한 번도 성공하지 않았으면 0을 반환합니다
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return fmt.Sprintf("%d", t.Unix())
+}
+
+func escapeLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscapeLabelValue(t *testing.T) {
+	tests := map[string]string{
+		"plain":         "plain",
+		`back\slash`:    `back\\slash`,
+		`say "hi"`:      `say \"hi\"`,
+		"two\nlines":    `two\nlines`,
+		"\\\"\n":        `\\\"\n`,
+		"한글_테이블":        "한글_테이블",
+		`already \n`:    `already \\n`,
+		"trailing\\":    `trailing\\`,
+		"quote\"inside": `quote\"inside`,
+	}
+	for value, want := range tests {
+		if got := escapeLabelValue(value); got != want {
+			t.Errorf("escapeLabelValue(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestBackupMetricsWriteTo(t *testing.T) {
+	success := time.Unix(1700000000, 0)
+	m := NewBackupMetrics(`sh"op`)
+	m.RecordTable(TableBackupResult{TableName: "users", Method: "integer_pk_cursor", RowCount: 10, Bytes: 2048, Duration: 1500 * time.Millisecond})
+	m.RecordTable(TableBackupResult{TableName: "we\\ird\n", Error: errors.New("boom"), Duration: time.Second})
+	m.RecordTable(TableBackupResult{TableName: "logs", Method: "simple", Error: context.Canceled})
+	m.RecordRun(4*time.Second, 10, 1, 1, nil)
+	m.RecordRun(2*time.Second, 10, 1, 0, nil)
+	// 완료 시각은 고정값으로 바꿔 출력을 비교
+	m.lastSuccess = success
+	m.tables["users"].LastSuccess = success
+
+	var out strings.Builder
+	n, err := m.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, out.Len())
+	}
+
+	want := `# HELP goback_backup_runs_total 백업 실행 횟수
+# TYPE goback_backup_runs_total counter
+goback_backup_runs_total{database="sh\"op"} 2
+# HELP goback_backup_failures_total 실패했거나 일부 테이블이 누락된 백업 실행 횟수
+# TYPE goback_backup_failures_total counter
+goback_backup_failures_total{database="sh\"op"} 1
+# HELP goback_backup_last_success_timestamp_seconds 마지막 성공한 백업의 완료 시각 (unix time)
+# TYPE goback_backup_last_success_timestamp_seconds gauge
+goback_backup_last_success_timestamp_seconds{database="sh\"op"} 1700000000
+# HELP goback_backup_last_duration_seconds 마지막 백업 실행의 소요 시간
+# TYPE goback_backup_last_duration_seconds gauge
+goback_backup_last_duration_seconds{database="sh\"op"} 2
+# HELP goback_backup_last_rows 마지막 백업 실행에서 백업된 총 행 수
+# TYPE goback_backup_last_rows gauge
+goback_backup_last_rows{database="sh\"op"} 10
+# HELP goback_backup_last_rows_per_second 마지막 백업 실행의 평균 처리량
+# TYPE goback_backup_last_rows_per_second gauge
+goback_backup_last_rows_per_second{database="sh\"op"} 5
+# HELP goback_backup_last_tables 마지막 백업 실행의 상태별 테이블 수
+# TYPE goback_backup_last_tables gauge
+goback_backup_last_tables{database="sh\"op",status="success"} 1
+goback_backup_last_tables{database="sh\"op",status="failed"} 0
+# HELP goback_table_backup_duration_seconds 테이블별 마지막 백업 소요 시간
+# TYPE goback_table_backup_duration_seconds gauge
+goback_table_backup_duration_seconds{database="sh\"op",table="logs",method="simple"} 0
+goback_table_backup_duration_seconds{database="sh\"op",table="users",method="integer_pk_cursor"} 1.5
+goback_table_backup_duration_seconds{database="sh\"op",table="we\\ird\n",method="unknown"} 1
+# HELP goback_table_backup_rows 테이블별 마지막 성공한 백업의 행 수
+# TYPE goback_table_backup_rows gauge
+goback_table_backup_rows{database="sh\"op",table="logs",method="simple"} 0
+goback_table_backup_rows{database="sh\"op",table="users",method="integer_pk_cursor"} 10
+goback_table_backup_rows{database="sh\"op",table="we\\ird\n",method="unknown"} 0
+# HELP goback_table_backup_bytes 테이블별 마지막 성공한 백업의 출력 크기
+# TYPE goback_table_backup_bytes gauge
+goback_table_backup_bytes{database="sh\"op",table="logs",method="simple"} 0
+goback_table_backup_bytes{database="sh\"op",table="users",method="integer_pk_cursor"} 2048
+goback_table_backup_bytes{database="sh\"op",table="we\\ird\n",method="unknown"} 0
+# HELP goback_table_backup_last_success_timestamp_seconds 테이블별 마지막 성공한 백업의 완료 시각 (unix time)
+# TYPE goback_table_backup_last_success_timestamp_seconds gauge
+goback_table_backup_last_success_timestamp_seconds{database="sh\"op",table="logs"} 0
+goback_table_backup_last_success_timestamp_seconds{database="sh\"op",table="users"} 1700000000
+goback_table_backup_last_success_timestamp_seconds{database="sh\"op",table="we\\ird\n"} 0
+# HELP goback_table_backup_failures_total 테이블별 백업 실패 횟수
+# TYPE goback_table_backup_failures_total counter
+goback_table_backup_failures_total{database="sh\"op",table="logs"} 0
+goback_table_backup_failures_total{database="sh\"op",table="users"} 0
+goback_table_backup_failures_total{database="sh\"op",table="we\\ird\n"} 1
+# HELP goback_table_backup_cancellations_total 다른 테이블의 실패나 중단 신호로 취소된 테이블별 횟수
+# TYPE goback_table_backup_cancellations_total counter
+goback_table_backup_cancellations_total{database="sh\"op",table="logs"} 1
+goback_table_backup_cancellations_total{database="sh\"op",table="users"} 0
+goback_table_backup_cancellations_total{database="sh\"op",table="we\\ird\n"} 0
+`
+	if got := out.String(); got != want {
+		gotLines, wantLines := strings.Split(got, "\n"), strings.Split(want, "\n")
+		for i := 0; i < len(gotLines) && i < len(wantLines); i++ {
+			if gotLines[i] != wantLines[i] {
+				t.Fatalf("line %d:\n got %s\nwant %s", i+1, gotLines[i], wantLines[i])
+			}
+		}
+		t.Fatalf("output has %d lines, want %d", len(gotLines), len(wantLines))
+	}
+}
+
+// TestBackupMetricsHeaders 모든 메트릭이 HELP, TYPE 다음에 샘플이 오고 이름이 한 번씩만 선언되는지 확인합니다
+func TestBackupMetricsHeaders(t *testing.T) {
+	m := NewBackupMetrics("shop")
+	m.RecordTable(TableBackupResult{TableName: "users"})
+	var out strings.Builder
+	if _, err := m.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	declared := make(map[string]string)
+	current := ""
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			name := strings.Fields(line)[2]
+			if _, dup := declared[name]; dup {
+				t.Errorf("%s declared twice", name)
+			}
+			if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "# TYPE "+name+" ") {
+				t.Errorf("HELP for %s not followed by its TYPE", name)
+			}
+			current = name
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			declared[fields[2]] = fields[3]
+			if fields[3] == "counter" && !strings.HasSuffix(fields[2], "_total") {
+				t.Errorf("counter %s should end in _total", fields[2])
+			}
+			if fields[3] != "counter" && fields[3] != "gauge" {
+				t.Errorf("%s has type %s", fields[2], fields[3])
+			}
+		default:
+			if name, _, _ := strings.Cut(line, "{"); name != current {
+				t.Errorf("sample %q outside its metric block %s", line, current)
+			}
+		}
+	}
+}
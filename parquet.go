@@ -0,0 +1,371 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+)
+
+// parquetColumn MySQL 컬럼 하나를 Parquet 리프 컬럼으로 변환하는 방법
+type parquetColumn struct {
+	name     string
+	index    int                                            // 스키마에서의 리프 컬럼 위치 (Group은 이름순으로 정렬됨)
+	optional bool                                           // NULL 허용 여부
+	convert  func(value interface{}) (parquet.Value, error) // NULL이 아닌 값을 Parquet 값으로 변환
+}
+
+// parquetNode MySQL 컬럼 타입에 맞는 Parquet 노드와 값 변환 함수를 만듭니다
+// DECIMAL은 정밀도에 따라 INT32/INT64/FIXED_LEN_BYTE_ARRAY, 날짜와 시각은 DATE/TIMESTAMP(마이크로초),
+// BIT는 UINT64, 바이너리 타입은 BYTE_ARRAY, JSON은 JSON, 나머지 문자열 계열(TIME, ENUM, SET 포함)은 UTF8 STRING으로 기록합니다
+func parquetNode(column *sql.ColumnType) (parquet.Node, func(interface{}) (parquet.Value, error)) {
+	typeName := column.DatabaseTypeName()
+	unsigned := strings.HasPrefix(typeName, "UNSIGNED ")
+	typeName = strings.TrimPrefix(typeName, "UNSIGNED ")
+
+	switch typeName {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "YEAR":
+		if unsigned {
+			return parquet.Uint(32), func(v interface{}) (parquet.Value, error) {
+				n, err := strconv.ParseUint(formatTextValue(v), 10, 32)
+				return parquet.Int32Value(int32(uint32(n))), err
+			}
+		}
+		return parquet.Int(32), func(v interface{}) (parquet.Value, error) {
+			n, err := strconv.ParseInt(formatTextValue(v), 10, 32)
+			return parquet.Int32Value(int32(n)), err
+		}
+	case "BIGINT":
+		if unsigned {
+			return parquet.Uint(64), func(v interface{}) (parquet.Value, error) {
+				n, err := strconv.ParseUint(formatTextValue(v), 10, 64)
+				return parquet.Int64Value(int64(n)), err
+			}
+		}
+		return parquet.Int(64), func(v interface{}) (parquet.Value, error) {
+			n, err := strconv.ParseInt(formatTextValue(v), 10, 64)
+			return parquet.Int64Value(n), err
+		}
+	case "FLOAT":
+		return parquet.Leaf(parquet.FloatType), func(v interface{}) (parquet.Value, error) {
+			f, err := strconv.ParseFloat(formatTextValue(v), 32)
+			return parquet.FloatValue(float32(f)), err
+		}
+	case "DOUBLE":
+		return parquet.Leaf(parquet.DoubleType), func(v interface{}) (parquet.Value, error) {
+			f, err := strconv.ParseFloat(formatTextValue(v), 64)
+			return parquet.DoubleValue(f), err
+		}
+	case "DECIMAL":
+		precision, scale, ok := column.DecimalSize()
+		if !ok {
+			precision, scale = 65, 30 // MySQL DECIMAL 최대 크기
+		}
+		return parquetDecimal(int(precision), int(scale))
+	case "DATE":
+		return parquet.Date(), func(v interface{}) (parquet.Value, error) {
+			t, zero, err := parseTimeValue(v, "2006-01-02")
+			if zero {
+				return parquet.NullValue(), err
+			}
+			return parquet.Int32Value(int32(t.Unix() / 86400)), err
+		}
+	case "DATETIME", "TIMESTAMP":
+		return parquet.Timestamp(parquet.Microsecond), func(v interface{}) (parquet.Value, error) {
+			t, zero, err := parseTimeValue(v, "2006-01-02 15:04:05.999999")
+			if zero {
+				return parquet.NullValue(), err
+			}
+			return parquet.Int64Value(t.UnixMicro()), err
+		}
+	case "BIT":
+		return parquet.Uint(64), func(v interface{}) (parquet.Value, error) {
+			n, ok := bitValue([]byte(formatTextValue(v)))
+			if !ok {
+				return parquet.Value{}, fmt.Errorf("BIT 값이 64비트보다 깁니다")
+			}
+			return parquet.Int64Value(int64(n)), nil
+		}
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "GEOMETRY":
+		// GEOMETRY는 MySQL 내부 형식 (리틀엔디언 SRID 4바이트 + WKB)
+		return parquet.Leaf(parquet.ByteArrayType), func(v interface{}) (parquet.Value, error) {
+			return parquet.ByteArrayValue([]byte(formatTextValue(v))), nil
+		}
+	case "JSON":
+		return parquet.JSON(), func(v interface{}) (parquet.Value, error) {
+			return parquet.ByteArrayValue([]byte(formatTextValue(v))), nil
+		}
+	default:
+		return parquet.String(), func(v interface{}) (parquet.Value, error) {
+			return parquet.ByteArrayValue([]byte(formatTextValue(v))), nil
+		}
+	}
+}
+
+// parquetDecimal 정밀도가 9자리 이하면 INT32, 18자리 이하면 INT64, 그보다 크면 2의 보수 빅엔디언 FIXED_LEN_BYTE_ARRAY로 기록합니다
+func parquetDecimal(precision, scale int) (parquet.Node, func(interface{}) (parquet.Value, error)) {
+	switch {
+	case precision <= 9:
+		return parquet.Decimal(scale, precision, parquet.Int32Type), func(v interface{}) (parquet.Value, error) {
+			n, err := unscaledDecimal(formatTextValue(v), scale)
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			return parquet.Int32Value(int32(n.Int64())), nil
+		}
+	case precision <= 18:
+		return parquet.Decimal(scale, precision, parquet.Int64Type), func(v interface{}) (parquet.Value, error) {
+			n, err := unscaledDecimal(formatTextValue(v), scale)
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			return parquet.Int64Value(n.Int64()), nil
+		}
+	default:
+		// 부호 비트를 포함해 10^precision을 담을 수 있는 최소 바이트 수
+		maxValue := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+		length := maxValue.BitLen()/8 + 1
+		return parquet.Decimal(scale, precision, parquet.FixedLenByteArrayType(length)), func(v interface{}) (parquet.Value, error) {
+			n, err := unscaledDecimal(formatTextValue(v), scale)
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			return parquet.FixedLenByteArrayValue(twosComplement(n, length)), nil
+		}
+	}
+}
+
+// unscaledDecimal "123.45" 같은 십진수 문자열을 소수점 이하 scale자리의 정수로 변환합니다 (scale 2면 12345)
+func unscaledDecimal(s string, scale int) (*big.Int, error) {
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if strings.TrimLeft(intPart, "+-") == "" && fracPart == "" {
+		return nil, fmt.Errorf("DECIMAL 값으로 변환할 수 없습니다: %q", s)
+	}
+	if len(fracPart) > scale {
+		return nil, fmt.Errorf("소수점 이하 자릿수가 DECIMAL scale(%d)보다 큽니다: %s", scale, s)
+	}
+	digits := intPart + fracPart + strings.Repeat("0", scale-len(fracPart))
+	n, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("DECIMAL 값으로 변환할 수 없습니다: %s", s)
+	}
+	return n, nil
+}
+
+// twosComplement n을 length 바이트의 2의 보수 빅엔디언 표현으로 변환합니다
+func twosComplement(n *big.Int, length int) []byte {
+	if n.Sign() < 0 {
+		// 음수는 2^(8*length) + n
+		n = new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), uint(8*length)), n)
+	}
+	return n.FillBytes(make([]byte, length))
+}
+
+// parseTimeValue 서버가 보낸 날짜/시각 텍스트(세션 시간대 +00:00)를 시각으로 변환합니다
+// 0000-00-00 같은 값은 Parquet 날짜로 나타낼 수 없으므로 zero를 true로 반환합니다 (NULL로 기록)
+func parseTimeValue(value interface{}, layout string) (t time.Time, zero bool, err error) {
+	if t, ok := value.(time.Time); ok {
+		return t, false, nil
+	}
+	text := formatTextValue(value)
+	if isZeroDate(text) {
+		return time.Time{}, true, nil
+	}
+	t, err = time.Parse(layout, text)
+	return t, false, err
+}
+
+// isTemporalType 0000-00-00 값이 NULL로 기록될 수 있는 날짜/시각 타입인지 여부
+func isTemporalType(column *sql.ColumnType) bool {
+	switch column.DatabaseTypeName() {
+	case "DATE", "DATETIME", "TIMESTAMP":
+		return true
+	}
+	return false
+}
+
+// parquetEncoder 행을 Apache Parquet 파일로 기록합니다
+// 행 그룹은 BatchSize 행마다 만들고, fileRows가 0보다 크면 그 행 수마다 다음 파일(-00001.parquet, ...)로 나눕니다
+type parquetEncoder struct {
+	writer   *parquet.Writer
+	columns  []parquetColumn
+	first    *partWriter // 첫 번째 파일 (호출한 쪽이 닫음)
+	current  *partWriter // 지금 기록 중인 파일
+	fileRows int64
+	rows     int64 // 현재 파일에 기록한 행 수
+	files    int   // 지금까지 연 파일 수
+	row      parquet.Row
+}
+
+func newParquetEncoder(out *partWriter, tableName string, columns []*sql.ColumnType, batchSize, fileRows int) (*parquetEncoder, error) {
+	// 이전 실행에서 나뉘어 기록된 파일이 남아있으면 섞이지 않도록 삭제
+	if err := removeParquetSplits(out.Path()); err != nil {
+		return nil, err
+	}
+
+	group := parquet.Group{}
+	converters := make(map[string]func(interface{}) (parquet.Value, error), len(columns))
+	optional := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		node, convert := parquetNode(column)
+		// 날짜/시각은 NOT NULL이어도 0000-00-00을 NULL로 기록할 수 있도록 OPTIONAL
+		nullable, ok := column.Nullable()
+		if nullable || !ok || isTemporalType(column) {
+			node = parquet.Optional(node)
+		}
+		group[column.Name()] = node
+		converters[column.Name()] = convert
+		optional[column.Name()] = nullable || !ok || isTemporalType(column)
+	}
+	schema := parquet.NewSchema(tableName, group)
+
+	enc := &parquetEncoder{
+		columns:  make([]parquetColumn, len(columns)),
+		first:    out,
+		current:  out,
+		fileRows: int64(fileRows),
+		files:    1,
+		row:      make(parquet.Row, len(columns)),
+	}
+	for i, column := range columns {
+		leaf, ok := schema.Lookup(column.Name())
+		if !ok {
+			return nil, fmt.Errorf("Parquet 스키마에서 컬럼 '%s'을(를) 찾을 수 없습니다", column.Name())
+		}
+		enc.columns[i] = parquetColumn{
+			name:     column.Name(),
+			index:    leaf.ColumnIndex,
+			optional: optional[column.Name()],
+			convert:  converters[column.Name()],
+		}
+	}
+
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	enc.writer = parquet.NewWriter(out,
+		schema,
+		parquet.MaxRowsPerRowGroup(int64(batchSize)),
+		parquet.Compression(&snappy.Codec{}),
+		parquet.CreatedBy("goback", "", ""),
+	)
+	return enc, nil
+}
+
+func (e *parquetEncoder) WriteRow(values []interface{}) error {
+	if e.fileRows > 0 && e.rows >= e.fileRows {
+		if err := e.nextFile(); err != nil {
+			return err
+		}
+	}
+
+	for i, column := range e.columns {
+		definitionLevel := 0
+		if column.optional {
+			definitionLevel = 1
+		}
+
+		if values[i] == nil {
+			if !column.optional {
+				return fmt.Errorf("NOT NULL 컬럼 '%s'에 NULL 값이 있습니다", column.name)
+			}
+			e.row[column.index] = parquet.NullValue().Level(0, 0, column.index)
+			continue
+		}
+
+		value, err := column.convert(values[i])
+		if err != nil {
+			return fmt.Errorf("컬럼 '%s' 값을 Parquet으로 변환할 수 없습니다: %v", column.name, err)
+		}
+		if value.IsNull() {
+			// 0000-00-00처럼 Parquet으로 나타낼 수 없는 값 (OPTIONAL 컬럼만 해당)
+			definitionLevel = 0
+		}
+		e.row[column.index] = value.Level(0, definitionLevel, column.index)
+	}
+
+	if _, err := e.writer.WriteRows([]parquet.Row{e.row}); err != nil {
+		return err
+	}
+	e.rows++
+	return nil
+}
+
+// Flush 행 그룹은 MaxRowsPerRowGroup에 맞춰 기록되므로 배치마다 할 일이 없음
+func (e *parquetEncoder) Flush() error {
+	return nil
+}
+
+// Close 남은 행 그룹과 파일 끝의 메타데이터를 기록하고, 나뉘어 연 파일을 닫습니다
+func (e *parquetEncoder) Close() error {
+	if err := e.writer.Close(); err != nil {
+		return err
+	}
+	return e.closeSplit()
+}
+
+// nextFile 현재 파일을 마무리하고 다음 파일에 이어서 기록합니다
+func (e *parquetEncoder) nextFile() error {
+	if err := e.writer.Close(); err != nil {
+		return err
+	}
+	if err := e.closeSplit(); err != nil {
+		return err
+	}
+
+	out, err := openPartWriter(parquetSplitPath(e.first.Path(), e.files), 0)
+	if err != nil {
+		return err
+	}
+	e.current = out
+	e.files++
+	e.rows = 0
+	e.writer.Reset(out)
+	return nil
+}
+
+// closeSplit 첫 번째 이후의 파일이면 디스크에 기록하고 닫습니다
+func (e *parquetEncoder) closeSplit() error {
+	if e.current == e.first {
+		return nil
+	}
+	if _, err := e.current.Sync(); err != nil {
+		e.current.Close()
+		return fmt.Errorf("Parquet 파일 기록 실패: %v", err)
+	}
+	return e.current.Close()
+}
+
+// parquetSplitPath 테이블의 n번째 추가 Parquet 파일 경로 (00003_users.parquet -> 00003_users-00001.parquet)
+func parquetSplitPath(dataPath string, n int) string {
+	return fmt.Sprintf("%s-%05d.parquet", strings.TrimSuffix(dataPath, filepath.Ext(dataPath)), n)
+}
+
+// parquetFiles 테이블의 Parquet 파일 경로 목록 (첫 번째 파일과 나뉘어 기록된 파일들)
+func parquetFiles(dataPath string) []string {
+	files := []string{dataPath}
+	for n := 1; ; n++ {
+		path := parquetSplitPath(dataPath, n)
+		if !fileExists(path) {
+			return files
+		}
+		files = append(files, path)
+	}
+}
+
+// removeParquetSplits 테이블을 처음부터 다시 기록하기 전에 나뉘어 기록된 파일을 삭제합니다
+func removeParquetSplits(dataPath string) error {
+	for _, path := range parquetFiles(dataPath)[1:] {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("이전 Parquet 파일 삭제 실패: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestUnscaledDecimal(t *testing.T) {
+	tests := []struct {
+		value string
+		scale int
+		want  string
+	}{
+		{"123.45", 2, "12345"},
+		{"123.4", 2, "12340"},
+		{"123", 2, "12300"},
+		{"-0.01", 2, "-1"},
+		{"-123.45", 3, "-123450"},
+		{"0", 0, "0"},
+		{"99999999999999999999999999999999999999", 0, "99999999999999999999999999999999999999"},
+		{"-9999999999999999999999999999.9999999999", 10, "-99999999999999999999999999999999999999"},
+	}
+	for _, tt := range tests {
+		got, err := unscaledDecimal(tt.value, tt.scale)
+		if err != nil {
+			t.Errorf("unscaledDecimal(%q, %d): %v", tt.value, tt.scale, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("unscaledDecimal(%q, %d) = %s, want %s", tt.value, tt.scale, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"1.234", "abc", "1.2.3", "", ".", "-"} {
+		if _, err := unscaledDecimal(value, 2); err == nil {
+			t.Errorf("unscaledDecimal(%q, 2) succeeded, want error", value)
+		}
+	}
+}
+
+func TestTwosComplement(t *testing.T) {
+	tests := []struct {
+		n      int64
+		length int
+		want   []byte
+	}{
+		{0, 2, []byte{0x00, 0x00}},
+		{1, 2, []byte{0x00, 0x01}},
+		{-1, 2, []byte{0xff, 0xff}},
+		{127, 1, []byte{0x7f}},
+		{-128, 1, []byte{0x80}},
+		{-129, 2, []byte{0xff, 0x7f}},
+		{256, 3, []byte{0x00, 0x01, 0x00}},
+	}
+	for _, tt := range tests {
+		if got := twosComplement(big.NewInt(tt.n), tt.length); !bytes.Equal(got, tt.want) {
+			t.Errorf("twosComplement(%d, %d) = %x, want %x", tt.n, tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestParquetDecimal(t *testing.T) {
+	// 정밀도 9/18 경계에서 물리 타입이 바뀜
+	node, encode := parquetDecimal(9, 2)
+	v, err := encode([]byte("-9999999.99"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Type().Kind().String() != "INT32" || v.Int32() != -999999999 {
+		t.Errorf("DECIMAL(9,2): kind %s, value %d", node.Type().Kind(), v.Int32())
+	}
+
+	node, encode = parquetDecimal(18, 0)
+	if v, err = encode("999999999999999999"); err != nil {
+		t.Fatal(err)
+	}
+	if node.Type().Kind().String() != "INT64" || v.Int64() != 999999999999999999 {
+		t.Errorf("DECIMAL(18,0): kind %s, value %d", node.Type().Kind(), v.Int64())
+	}
+
+	node, encode = parquetDecimal(19, 0)
+	if node.Type().Kind().String() != "FIXED_LEN_BYTE_ARRAY" || node.Type().Length() != 9 {
+		t.Errorf("DECIMAL(19,0): kind %s, length %d", node.Type().Kind(), node.Type().Length())
+	}
+
+	node, encode = parquetDecimal(65, 30)
+	length := node.Type().Length()
+	for _, value := range []string{
+		strings.Repeat("9", 35) + "." + strings.Repeat("9", 30),
+		"-" + strings.Repeat("9", 35) + "." + strings.Repeat("9", 30),
+		"-0.000000000000000000000000000001",
+	} {
+		v, err := encode(value)
+		if err != nil {
+			t.Fatalf("DECIMAL(65,30) %s: %v", value, err)
+		}
+		raw := v.ByteArray()
+		if len(raw) != length {
+			t.Fatalf("DECIMAL(65,30) %s: %d bytes, want %d", value, len(raw), length)
+		}
+		// 빅엔디언 2의 보수를 되돌려 원래 값과 비교
+		got := new(big.Int).SetBytes(raw)
+		if raw[0]&0x80 != 0 {
+			got.Sub(got, new(big.Int).Lsh(big.NewInt(1), uint(8*length)))
+		}
+		want, _ := unscaledDecimal(value, 30)
+		if got.Cmp(want) != 0 {
+			t.Errorf("DECIMAL(65,30) %s: decoded %s, want %s", value, got, want)
+		}
+	}
+
+	if _, err := encode("1.0000000000000000000000000000001"); err == nil {
+		t.Error("value with more fractional digits than the scale succeeded, want error")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// largeTableRows 추정 행 수가 이보다 많으면 대용량 테이블로 보고 커서 방식이나 파티션별 병렬 백업을 씁니다
+const largeTableRows = 10000
+
+// TablePartition 파티션 하나 (서브파티션은 상위 파티션에 합쳐 하나로 셈)
+type TablePartition struct {
+	Name          string
+	EstimatedRows int64
+}
+
+// backupUnit 워커 하나가 파트 파일 하나에 기록하는 병렬 백업 단위
+// 파티션 테이블은 파티션마다 단위가 되고, 첫 파티션 단위가 테이블 구조를 함께 기록합니다
+type backupUnit struct {
+	Table     string
+	Partition string // 이 파티션만 백업 (비어있으면 테이블 전체)
+	Structure bool   // 테이블 구조를 기록하는 단위
+
+	// Info 파티션 단위가 함께 쓰는 테이블 분석 결과 (계획할 때 테이블당 한 번 분석, nil이면 백업할 때 분석)
+	Info *TableInfo
+}
+
+// Name 로그, 진행률, 체크포인트에 쓰는 단위 이름
+func (u backupUnit) Name() string {
+	return unitName(u.Table, u.Partition)
+}
+
+// partFileName 단위의 파트 파일 이름 (파티션 단위는 테이블 이름 뒤에 파티션 이름)
+func (u backupUnit) partFileName(index int, ext string) string {
+	if u.Partition == "" {
+		return partFileName(index, u.Table, ext)
+	}
+	return partFileName(index, u.Table+"_"+u.Partition, ext)
+}
+
+// unitName 테이블 또는 "테이블 파티션 p0" 형식의 단위 이름
+func unitName(tableName, partition string) string {
+	if partition == "" {
+		return tableName
+	}
+	return tableName + " 파티션 " + partition
+}
+
+// loadPartitions INFORMATION_SCHEMA.PARTITIONS에서 테이블별 파티션을 정의 순서대로 조회합니다 (tableName이 비어있으면 모든 테이블)
+// 파티션이 없는 테이블은 결과에 없습니다
+func (mb *MySQLBackup) loadPartitions(ctx context.Context, tableName string) (map[string][]TablePartition, error) {
+	query := `
+		SELECT TABLE_NAME, PARTITION_NAME, COALESCE(SUM(TABLE_ROWS), 0)
+		FROM INFORMATION_SCHEMA.PARTITIONS
+		WHERE TABLE_SCHEMA = ? AND PARTITION_NAME IS NOT NULL`
+	args := []interface{}{mb.config.Database}
+	if tableName != "" {
+		query += " AND TABLE_NAME = ?"
+		args = append(args, tableName)
+	}
+	query += `
+		GROUP BY TABLE_NAME, PARTITION_NAME, PARTITION_ORDINAL_POSITION
+		ORDER BY TABLE_NAME, PARTITION_ORDINAL_POSITION`
+
+	rows, err := mb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	partitions := make(map[string][]TablePartition)
+	for rows.Next() {
+		var table string
+		var partition TablePartition
+		if err := rows.Scan(&table, &partition.Name, &partition.EstimatedRows); err != nil {
+			return nil, err
+		}
+		partitions[table] = append(partitions[table], partition)
+	}
+	return partitions, rows.Err()
+}
+
+// planBackupUnits 테이블 순서를 유지하며 병렬 백업 단위를 만듭니다
+// 파티션이 둘 이상인 대용량 테이블은 파티션마다 단위를 나눠, 정수 커서 컬럼이 없는 테이블도 여러 워커가 나눠 읽습니다
+func planBackupUnits(tables []string, partitions map[string][]TablePartition) []backupUnit {
+	units := make([]backupUnit, 0, len(tables))
+	for _, table := range tables {
+		parts := partitions[table]
+		var rows int64
+		for _, p := range parts {
+			rows += p.EstimatedRows
+		}
+		if len(parts) < 2 || rows <= largeTableRows {
+			units = append(units, backupUnit{Table: table, Structure: true})
+			continue
+		}
+		for i, p := range parts {
+			units = append(units, backupUnit{Table: table, Partition: p.Name, Structure: i == 0})
+		}
+	}
+	return units
+}
+
+// groupUnitResults 단위별 결과를 테이블별 결과로 합칩니다 (행 수, 크기, 소요 시간은 합계)
+// 파티션 하나라도 실패한 테이블은 일부 데이터만 복원되지 않도록 나머지 파티션 단위도 같은 오류로 표시합니다
+func groupUnitResults(results []TableBackupResult) []TableBackupResult {
+	var tables []TableBackupResult
+	positions := make(map[string]int)
+	for _, result := range results {
+		err := result.Error
+		if err != nil && result.Partition != "" {
+			err = fmt.Errorf("파티션 %s: %w", result.Partition, err)
+		}
+
+		i, ok := positions[result.TableName]
+		if !ok {
+			positions[result.TableName] = len(tables)
+			result.Partition = ""
+			result.Error = err
+			tables = append(tables, result)
+			continue
+		}
+		table := &tables[i]
+		table.RowCount += result.RowCount
+		table.Bytes += result.Bytes
+		table.Duration += result.Duration
+		// 취소된 파티션보다 실제로 실패한 파티션의 오류를 남김
+		if table.Error == nil || (errors.Is(table.Error, context.Canceled) && err != nil && !errors.Is(err, context.Canceled)) {
+			table.Error = err
+		}
+	}
+
+	for i := range results {
+		if err := tables[positions[results[i].TableName]].Error; err != nil && results[i].Error == nil {
+			results[i].Error = err
+		}
+	}
+	return tables
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanBackupUnits(t *testing.T) {
+	partitions := map[string][]TablePartition{
+		"events":   {{Name: "p2023", EstimatedRows: 8000}, {Name: "p2024", EstimatedRows: 8000}, {Name: "pmax", EstimatedRows: 0}},
+		"small":    {{Name: "p0", EstimatedRows: 5000}, {Name: "p1", EstimatedRows: 5000}},
+		"single":   {{Name: "p0", EstimatedRows: 1000000}},
+		"unlisted": {{Name: "p0", EstimatedRows: 1000000}, {Name: "p1", EstimatedRows: 1}},
+	}
+	units := planBackupUnits([]string{"users", "events", "small", "single"}, partitions)
+
+	var got []string
+	for _, u := range units {
+		got = append(got, fmt.Sprintf("%s/%v", u.Name(), u.Structure))
+	}
+	want := []string{
+		"users/true",
+		"events 파티션 p2023/true",
+		"events 파티션 p2024/false",
+		"events 파티션 pmax/false",
+		// 행 수가 경계(largeTableRows) 이하이거나 파티션이 하나면 나누지 않음
+		"small/true",
+		"single/true",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("units =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if name := units[1].partFileName(3, ".sql"); name != "00003_events_p2023.sql" {
+		t.Errorf("partFileName = %s", name)
+	}
+	if name := units[0].partFileName(0, ".csv"); name != "00000_users.csv" {
+		t.Errorf("partFileName = %s", name)
+	}
+	if units := planBackupUnits(nil, partitions); len(units) != 0 {
+		t.Errorf("no tables gave %+v", units)
+	}
+}
+
+func TestGroupUnitResults(t *testing.T) {
+	results := []TableBackupResult{
+		{TableName: "users", RowCount: 10, Bytes: 100, Duration: time.Second},
+		{TableName: "events", Partition: "p0", RowCount: 5, Bytes: 50, Duration: time.Second},
+		{TableName: "events", Partition: "p1", RowCount: 7, Bytes: 70, Duration: 2 * time.Second},
+		{TableName: "logs", Partition: "p0", RowCount: 1},
+		{TableName: "logs", Partition: "p1", Error: context.Canceled},
+		{TableName: "logs", Partition: "p2", Error: errors.New("disk full")},
+		{TableName: "logs", Partition: "p3", RowCount: 2},
+	}
+	tables := groupUnitResults(results)
+	if len(tables) != 3 || tables[0].TableName != "users" || tables[1].TableName != "events" || tables[2].TableName != "logs" {
+		t.Fatalf("tables = %+v", tables)
+	}
+
+	events := tables[1]
+	if events.Partition != "" || events.Error != nil || events.RowCount != 12 || events.Bytes != 120 || events.Duration != 3*time.Second {
+		t.Errorf("events = %+v, want sums of both partitions", events)
+	}
+
+	// 취소된 파티션보다 실제로 실패한 파티션의 오류를 남김
+	logs := tables[2]
+	if logs.Error == nil || errors.Is(logs.Error, context.Canceled) || !strings.Contains(logs.Error.Error(), "파티션 p2: disk full") {
+		t.Errorf("logs error = %v, want the p2 failure", logs.Error)
+	}
+	if logs.RowCount != 3 {
+		t.Errorf("logs rows = %d, want 3", logs.RowCount)
+	}
+
+	// 같은 테이블의 성공한 파티션 단위도 실패로 표시되어 병합되지 않음
+	for _, i := range []int{3, 6} {
+		if results[i].Error == nil {
+			t.Errorf("logs partition %s kept no error", results[i].Partition)
+		}
+	}
+	if results[0].Error != nil || results[1].Error != nil || results[2].Error != nil {
+		t.Error("successful tables were marked as failed")
+	}
+}
+
+func TestGroupUnitResultsCancelledOnly(t *testing.T) {
+	results := []TableBackupResult{
+		{TableName: "logs", Partition: "p0"},
+		{TableName: "logs", Partition: "p1", Error: context.Canceled},
+	}
+	tables := groupUnitResults(results)
+	if len(tables) != 1 || !errors.Is(tables[0].Error, context.Canceled) {
+		t.Errorf("tables = %+v, want a cancelled table", tables)
+	}
+	if !errors.Is(results[0].Error, context.Canceled) {
+		t.Errorf("p0 error = %v, want the cancellation", results[0].Error)
+	}
+}
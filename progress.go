@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tableProgress 실행 중인 테이블 하나의 진행 상황
+type tableProgress struct {
+	estimated int64
+	done      atomic.Int64
+	resumed   int64 // 이전 실행에서 읽은 행 수 (처리 속도 계산에서 제외)
+	start     time.Time
+}
+
+// ProgressTracker 테이블별/전체 진행률을 추적합니다
+// nil 수신자에서도 안전하게 호출할 수 있어 진행률 표시가 꺼져 있어도 호출부를 바꿀 필요가 없습니다
+type ProgressTracker struct {
+	mu          sync.Mutex
+	start       time.Time
+	tables      map[string]*tableProgress
+	estimates   map[string]int64
+	totalTables int
+	finished    int
+	finishedRow int64
+	resumedRows int64           // 이전 실행에서 읽은 행 수 (처리 속도와 ETA 계산에서 제외)
+	done        map[string]bool // 종료를 기록한 테이블 (시작하지 못한 테이블 포함)
+}
+
+func NewProgressTracker(estimates map[string]int64, totalTables int) *ProgressTracker {
+	if estimates == nil {
+		estimates = make(map[string]int64)
+	}
+	return &ProgressTracker{
+		start:       time.Now(),
+		tables:      make(map[string]*tableProgress),
+		estimates:   estimates,
+		totalTables: totalTables,
+		done:        make(map[string]bool),
+	}
+}
+
+// StartTable 테이블 백업 시작을 기록합니다 (analyzeTable의 추정 행 수 사용)
+func (p *ProgressTracker) StartTable(name string, estimated int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tables[name] = &tableProgress{estimated: estimated, start: time.Now()}
+	p.estimates[name] = estimated
+}
+
+// AddRows 처리된 행 수를 누적합니다
+func (p *ProgressTracker) AddRows(name string, n int64) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.mu.Lock()
+	tp := p.tables[name]
+	p.mu.Unlock()
+
+	if tp != nil {
+		tp.done.Add(n)
+	}
+}
+
+// ResumeRows 체크포인트에서 이어가는 테이블이 이전 실행에서 읽은 행 수를 기록합니다
+// 진행률에는 포함하지만 이번 실행의 처리 속도에는 넣지 않아 ETA가 짧게 나오지 않도록 합니다
+func (p *ProgressTracker) ResumeRows(name string, n int64) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if tp := p.tables[name]; tp != nil {
+		tp.done.Add(n)
+		tp.resumed += n
+		p.resumedRows += n
+	}
+}
+
+// SkipTable 이전 실행에서 완료되어 건너뛰는 테이블을 rows 행으로 완료 처리합니다
+func (p *ProgressTracker) SkipTable(name string, rows int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done[name] {
+		return
+	}
+	p.done[name] = true
+	p.finished++
+	p.finishedRow += rows
+	p.resumedRows += rows
+	p.estimates[name] = rows
+}
+
+// FinishTable 테이블 백업 종료를 기록합니다
+// 분석 단계에서 실패해 StartTable을 부르지 못한 테이블도 끝난 테이블로 셉니다
+// 추정치는 실제로 읽은 행 수로 바꿔 남은 행 계산에서 빠지게 합니다
+func (p *ProgressTracker) FinishTable(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done[name] {
+		return
+	}
+	p.done[name] = true
+	p.finished++
+
+	var rows int64
+	if tp := p.tables[name]; tp != nil {
+		rows = tp.done.Load()
+		delete(p.tables, name)
+	}
+	p.finishedRow += rows
+	p.estimates[name] = rows
+}
+
+// progressLine 진행률 한 줄 (테이블 또는 전체)
+type progressLine struct {
+	Table     string
+	Done      int64
+	Estimated int64
+	Rate      float64 // 행/초
+	ETA       time.Duration
+	Elapsed   time.Duration
+}
+
+// Snapshot 실행 중인 테이블들과 전체 진행률을 반환합니다
+func (p *ProgressTracker) Snapshot() (progressLine, []progressLine) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var running []progressLine
+	totalDone := p.finishedRow
+	for name, tp := range p.tables {
+		done := tp.done.Load()
+		totalDone += done
+		running = append(running, resumedProgressLine(name, done, tp.estimated, tp.resumed, now.Sub(tp.start)))
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].Table < running[j].Table })
+
+	var totalEstimated int64
+	for _, est := range p.estimates {
+		totalEstimated += est
+	}
+
+	overall := resumedProgressLine("", totalDone, totalEstimated, p.resumedRows, now.Sub(p.start))
+	return overall, running
+}
+
+// resumedProgressLine 이전 실행에서 읽은 resumed 행을 뺀 나머지로 처리 속도와 ETA를 계산하고, 행 수와 진행률에는 포함합니다
+func resumedProgressLine(table string, done, estimated, resumed int64, elapsed time.Duration) progressLine {
+	line := newProgressLine(table, done-resumed, estimated-resumed, elapsed)
+	line.Done, line.Estimated = done, estimated
+	return line
+}
+
+func newProgressLine(table string, done, estimated int64, elapsed time.Duration) progressLine {
+	line := progressLine{Table: table, Done: done, Estimated: estimated, Elapsed: elapsed}
+	if elapsed > 0 {
+		line.Rate = float64(done) / elapsed.Seconds()
+	}
+	// 추정치는 INFORMATION_SCHEMA 기반이라 실제보다 작을 수 있으므로 초과하면 ETA를 0으로 둡니다
+	if line.Rate > 0 && estimated > done {
+		line.ETA = time.Duration(float64(estimated-done) / line.Rate * float64(time.Second))
+	}
+	return line
+}
+
+func (l progressLine) percent() float64 {
+	if l.Estimated <= 0 {
+		return 0
+	}
+	pct := float64(l.Done) / float64(l.Estimated) * 100
+	if pct > 100 {
+		pct = 100
+	}
+	return pct
+}
+
+func (l progressLine) String() string {
+	name := l.Table
+	if name == "" {
+		name = "전체"
+	}
+	return fmt.Sprintf("%-24s %12d / ~%-12d %5.1f%%  %9.0f행/초  ETA %s",
+		name, l.Done, l.Estimated, l.percent(), l.Rate, formatETA(l.ETA))
+}
+
+func formatETA(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+// Run 주기적으로 진행 상황을 출력합니다. done 채널이 닫히면 종료합니다
+// display가 있으면 터미널에 실시간으로 그리고, 없으면 로그 라인으로 남깁니다
+func (p *ProgressTracker) Run(done <-chan struct{}, interval time.Duration, display *ProgressDisplay, logger *slog.Logger) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			if display != nil {
+				display.Clear()
+			}
+			return
+		case <-ticker.C:
+			overall, running := p.Snapshot()
+			if display != nil {
+				lines := make([]string, 0, len(running)+1)
+				for _, line := range running {
+					lines = append(lines, line.String())
+				}
+				lines = append(lines, overall.String()+fmt.Sprintf("  [%d/%d 테이블]", p.finishedTables(), p.totalTables))
+				display.Update(lines)
+				continue
+			}
+
+			for _, line := range running {
+				logger.Info("테이블 진행 상황",
+					"table", line.Table,
+					"rows", line.Done,
+					"estimated_rows", line.Estimated,
+					"percent", fmt.Sprintf("%.1f", line.percent()),
+					"rows_per_sec", int64(line.Rate),
+					"eta", line.ETA.Round(time.Second),
+					"duration", line.Elapsed.Round(time.Second))
+			}
+			logger.Info("전체 진행 상황",
+				"tables_done", p.finishedTables(),
+				"tables", p.totalTables,
+				"rows", overall.Done,
+				"estimated_rows", overall.Estimated,
+				"percent", fmt.Sprintf("%.1f", overall.percent()),
+				"rows_per_sec", int64(overall.Rate),
+				"eta", overall.ETA.Round(time.Second),
+				"duration", overall.Elapsed.Round(time.Second))
+		}
+	}
+}
+
+func (p *ProgressTracker) finishedTables() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.finished
+}
+
+// ProgressDisplay 터미널 하단에 진행 상황 블록을 실시간으로 다시 그립니다
+// 로그 출력도 이 writer를 거치게 해서 로그가 진행 블록 위에 깔끔하게 쌓이도록 합니다
+type ProgressDisplay struct {
+	mu    sync.Mutex
+	out   io.Writer
+	lines []string
+	drawn int
+}
+
+func NewProgressDisplay(out io.Writer) *ProgressDisplay {
+	return &ProgressDisplay{out: out}
+}
+
+// Write 진행 블록을 지우고 로그를 쓴 뒤 블록을 다시 그립니다
+func (d *ProgressDisplay) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.erase()
+	n, err := d.out.Write(p)
+	d.draw()
+	return n, err
+}
+
+// Update 진행 블록 내용을 교체합니다
+func (d *ProgressDisplay) Update(lines []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.erase()
+	d.lines = lines
+	d.draw()
+}
+
+// Clear 진행 블록을 지웁니다
+func (d *ProgressDisplay) Clear() {
+	d.Update(nil)
+}
+
+func (d *ProgressDisplay) erase() {
+	if d.drawn == 0 {
+		return
+	}
+	// 커서를 블록 시작으로 올리고 화면 끝까지 지움
+	fmt.Fprintf(d.out, "\033[%dA\r\033[J", d.drawn)
+	d.drawn = 0
+}
+
+func (d *ProgressDisplay) draw() {
+	if len(d.lines) == 0 {
+		return
+	}
+	fmt.Fprint(d.out, strings.Join(d.lines, "\n")+"\n")
+	d.drawn = len(d.lines)
+}
+
+// isTerminal 파일이 터미널(문자 장치)인지 확인합니다
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewProgressLine(t *testing.T) {
+	tests := []struct {
+		name           string
+		done, estimate int64
+		elapsed        time.Duration
+		rate           float64
+		eta            time.Duration
+		percent        float64
+	}{
+		{"halfway", 500, 1000, 10 * time.Second, 50, 10 * time.Second, 50},
+		{"not started", 0, 1000, 10 * time.Second, 0, 0, 0},
+		{"no elapsed time", 100, 1000, 0, 0, 0, 10},
+		{"estimate exceeded", 1500, 1000, 10 * time.Second, 150, 0, 100},
+		{"no estimate", 100, 0, time.Second, 100, 0, 0},
+		{"done", 1000, 1000, 4 * time.Second, 250, 0, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := newProgressLine("t", tt.done, tt.estimate, tt.elapsed)
+			if line.Rate != tt.rate || line.ETA != tt.eta || line.percent() != tt.percent {
+				t.Errorf("rate %v, eta %v, percent %v; want %v, %v, %v", line.Rate, line.ETA, line.percent(), tt.rate, tt.eta, tt.percent)
+			}
+		})
+	}
+}
+
+func TestProgressLineString(t *testing.T) {
+	line := progressLine{Done: 250, Estimated: 1000, Rate: 50, ETA: 15*time.Second + 400*time.Millisecond}
+	got := line.String()
+	for _, want := range []string{"전체", "250 / ~1000", "25.0%", "50행/초", "ETA 15s"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, missing %q", got, want)
+		}
+	}
+	if got := formatETA(0); got != "-" {
+		t.Errorf("formatETA(0) = %q", got)
+	}
+}
+
+func TestResumedProgressLine(t *testing.T) {
+	// 이전 실행의 900행은 진행률에만 포함되고 처리 속도에는 들어가지 않음
+	line := resumedProgressLine("t", 1000, 2000, 900, 10*time.Second)
+	if line.Done != 1000 || line.Estimated != 2000 || line.percent() != 50 {
+		t.Errorf("done %d, estimated %d, percent %v", line.Done, line.Estimated, line.percent())
+	}
+	if line.Rate != 10 || line.ETA != 100*time.Second {
+		t.Errorf("rate %v, eta %v; want 10, 1m40s", line.Rate, line.ETA)
+	}
+}
+
+func TestProgressTrackerTableCount(t *testing.T) {
+	p := NewProgressTracker(map[string]int64{"done": 100, "skipped": 500, "failed": 300, "running": 100}, 4)
+
+	// 이전 실행에서 완료된 테이블
+	p.SkipTable("skipped", 400)
+	// 분석 단계에서 실패해 시작하지 못한 테이블
+	p.FinishTable("failed")
+	p.StartTable("done", 100)
+	p.AddRows("done", 120)
+	p.FinishTable("done")
+	p.FinishTable("done") // 중복 호출은 한 번만 셈
+	p.StartTable("running", 100)
+	p.AddRows("running", 30)
+
+	if got := p.finishedTables(); got != 3 {
+		t.Errorf("finished tables = %d, want 3", got)
+	}
+	overall, running := p.Snapshot()
+	if len(running) != 1 || running[0].Table != "running" || running[0].Done != 30 {
+		t.Errorf("running = %+v", running)
+	}
+	// 끝난 테이블의 추정치는 실제 행 수로 바뀜: 400 + 0 + 120 + 100
+	if overall.Done != 550 || overall.Estimated != 620 {
+		t.Errorf("overall done %d / %d, want 550 / 620", overall.Done, overall.Estimated)
+	}
+
+	p.FinishTable("running")
+	if overall, _ := p.Snapshot(); overall.percent() != 100 || p.finishedTables() != 4 {
+		t.Errorf("after all tables: %v%%, %d tables", overall.percent(), p.finishedTables())
+	}
+}
+
+func TestProgressTrackerResumeRows(t *testing.T) {
+	p := NewProgressTracker(nil, 1)
+	p.StartTable("orders", 1000)
+	p.ResumeRows("orders", 600)
+	p.AddRows("orders", 100)
+	p.start = time.Now().Add(-10 * time.Second)
+
+	overall, running := p.Snapshot()
+	if running[0].Done != 700 || overall.Done != 700 {
+		t.Errorf("done = %d / %d, want 700", running[0].Done, overall.Done)
+	}
+	// 이번 실행에서 읽은 100행만으로 속도를 계산 (약 10행/초)
+	if overall.Rate < 9 || overall.Rate > 11 {
+		t.Errorf("overall rate = %v, want about 10", overall.Rate)
+	}
+
+	var nilTracker *ProgressTracker
+	nilTracker.SkipTable("t", 1)
+	nilTracker.ResumeRows("t", 1)
+	nilTracker.FinishTable("t")
+}
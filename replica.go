@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// replicaRestartTimeout 백업이 취소되었어도 복제를 다시 시작할 수 있도록 주는 시간
+const replicaRestartTimeout = 30 * time.Second
+
+// ReplicaStatus SHOW REPLICA STATUS (또는 SHOW SLAVE STATUS) 한 행
+// MySQL 8.0.22부터 Source/Replica로 이름이 바뀐 컬럼은 예전 이름으로도 찾습니다
+type ReplicaStatus map[string]sql.NullString
+
+// get 주어진 이름 중 처음으로 있는 컬럼 값
+func (s ReplicaStatus) get(names ...string) sql.NullString {
+	for _, name := range names {
+		if value, ok := s[name]; ok {
+			return value
+		}
+	}
+	return sql.NullString{}
+}
+
+// Lag 소스보다 뒤처진 시간 (SQL 스레드가 멈춰 있는 등 알 수 없으면 ok가 false)
+func (s ReplicaStatus) Lag() (time.Duration, bool) {
+	value := s.get("Seconds_Behind_Source", "Seconds_Behind_Master")
+	if !value.Valid {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(value.String, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// SQLThreadRunning SQL 스레드가 실행 중인지 여부
+func (s ReplicaStatus) SQLThreadRunning() bool {
+	return s.get("Replica_SQL_Running", "Slave_SQL_Running").String == "Yes"
+}
+
+// Coordinates 레플리카에 적용된 소스의 바이너리 로그 위치 (SQL 스레드가 실행한 지점)
+func (s ReplicaStatus) Coordinates() (host, file, pos, gtid string) {
+	return s.get("Source_Host", "Master_Host").String,
+		s.get("Relay_Source_Log_File", "Relay_Master_Log_File").String,
+		s.get("Exec_Source_Log_Pos", "Exec_Master_Log_Pos").String,
+		strings.ReplaceAll(s.get("Executed_Gtid_Set").String, "\n", "")
+}
+
+// ReplicaGuard 레플리카에서 백업할 때 복제 지연을 감시하고 SQL 스레드를 멈추거나 다시 시작합니다
+// 백업 중이 아니면 nil이며, nil이면 모든 메서드가 아무것도 하지 않습니다
+type ReplicaGuard struct {
+	db       *sql.DB
+	logger   *slog.Logger
+	maxLag   time.Duration
+	interval time.Duration
+	maxWait  time.Duration // 지연이 내려오기를 기다리는 최대 시간 (0이면 무제한)
+	legacy   bool          // SHOW SLAVE STATUS / STOP SLAVE 문법 사용 (MySQL 8.0.22 미만, MariaDB)
+
+	// status 복제 상태 조회 (nil이면 Status, 테스트에서 서버 없이 바꿔 끼움)
+	status func(ctx context.Context) (ReplicaStatus, error)
+
+	stoppedSQLThread bool // 이 백업이 SQL 스레드를 멈췄는지 여부 (끝나면 다시 시작)
+
+	mu        sync.Mutex
+	lastCheck time.Time // 지연이 허용 범위로 확인된 마지막 시각
+}
+
+// Status 복제 상태를 조회합니다 (레플리카가 아니면 오류)
+func (g *ReplicaGuard) Status(ctx context.Context) (ReplicaStatus, error) {
+	query := "SHOW REPLICA STATUS"
+	if g.legacy {
+		query = "SHOW SLAVE STATUS"
+	}
+	rows, err := g.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("복제 상태가 없습니다 (레플리카가 아닌 서버)")
+	}
+
+	values := make([]sql.NullString, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+
+	return parseReplicaStatus(columns, values), rows.Err()
+}
+
+// parseReplicaStatus 조회한 한 행을 컬럼 이름으로 찾을 수 있는 ReplicaStatus로 만듭니다
+func parseReplicaStatus(columns []string, values []sql.NullString) ReplicaStatus {
+	status := make(ReplicaStatus, len(columns))
+	for i, column := range columns {
+		if i < len(values) {
+			status[column] = values[i]
+		}
+	}
+	return status
+}
+
+// WaitForLag 복제 지연이 허용 범위를 넘으면 내려갈 때까지 배치를 멈춥니다
+// 서버 부담을 줄이기 위해 확인 주기 안에서는 다시 조회하지 않으며, SQL 스레드를 멈춘 경우 데이터가 고정되어 있으므로 확인하지 않습니다
+func (g *ReplicaGuard) WaitForLag(ctx context.Context, tableName string) error {
+	if g == nil || g.stoppedSQLThread {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if time.Since(g.lastCheck) < g.interval {
+		return nil
+	}
+
+	query := g.status
+	if query == nil {
+		query = g.Status
+	}
+
+	var pausedAt time.Time
+	for {
+		status, err := query(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("복제 상태 조회 실패: %v", err)
+		}
+
+		lag, ok := status.Lag()
+		if !ok {
+			// 복제가 멈춰 지연을 알 수 없으면 기다려도 나아지지 않으므로 경고만 남김
+			g.logger.Warn("복제 지연을 알 수 없습니다 (복제 스레드 확인 필요)", "table", tableName)
+			break
+		}
+		if lag <= g.maxLag {
+			if !pausedAt.IsZero() {
+				g.logger.Info("복제 지연이 내려가 백업을 재개합니다", "table", tableName, "lag", lag)
+			}
+			break
+		}
+
+		if pausedAt.IsZero() {
+			g.logger.Warn("복제 지연이 허용 범위를 넘어 배치를 일시 정지합니다",
+				"table", tableName, "lag", lag, "max_lag", g.maxLag)
+			pausedAt = time.Now()
+		} else if g.maxWait > 0 && time.Since(pausedAt) >= g.maxWait {
+			// 복제가 따라잡지 못하는 동안 연결과 스냅샷을 계속 잡고 있지 않도록 포기
+			return fmt.Errorf("복제 지연이 %s 동안 허용 범위(%s)로 내려오지 않았습니다 (현재 %s)", g.maxWait, g.maxLag, lag)
+		}
+		if err := sleepContext(ctx, g.interval); err != nil {
+			return err
+		}
+	}
+
+	g.lastCheck = time.Now()
+	return nil
+}
+
+// startReplicaGuard 레플리카 모드 백업을 준비합니다
+// 복제 지연이 허용 범위로 내려올 때까지 기다린 뒤, 설정되어 있으면 SQL 스레드를 멈춰 데이터를 고정하고 헤더에 넣을 소스 위치를 반환합니다
+func (mb *MySQLBackup) startReplicaGuard(ctx context.Context) (*ReplicaGuard, string, error) {
+	g := &ReplicaGuard{
+		db:       mb.db,
+		logger:   mb.logger,
+		maxLag:   mb.config.ReplicaMaxLag,
+		interval: mb.config.ReplicaCheckInterval,
+		maxWait:  mb.config.ReplicaMaxWait,
+	}
+	if g.interval <= 0 {
+		g.interval = 5 * time.Second
+	}
+
+	status, err := g.Status(ctx)
+	if err != nil {
+		// 8.0.22 미만은 예전 문법만 지원
+		g.legacy = true
+		if status, err = g.Status(ctx); err != nil {
+			return nil, "", fmt.Errorf("복제 상태 조회 실패: %v", err)
+		}
+	}
+
+	if err := g.WaitForLag(ctx, ""); err != nil {
+		return nil, "", err
+	}
+
+	if mb.config.ReplicaStopSQLThread == "on" && status.SQLThreadRunning() {
+		stop := "STOP REPLICA SQL_THREAD"
+		if g.legacy {
+			stop = "STOP SLAVE SQL_THREAD"
+		}
+		if _, err := mb.db.ExecContext(ctx, stop); err != nil {
+			return nil, "", fmt.Errorf("SQL 스레드 정지 실패: %v", err)
+		}
+		g.stoppedSQLThread = true
+		mb.logger.Info("데이터를 고정하기 위해 복제 SQL 스레드를 멈췄습니다")
+	}
+
+	// SQL 스레드를 멈춘 뒤의 위치가 백업 데이터와 정확히 일치
+	if status, err = g.Status(ctx); err != nil {
+		g.Stop()
+		return nil, "", fmt.Errorf("복제 상태 조회 실패: %v", err)
+	}
+	host, file, pos, gtid := status.Coordinates()
+	lag, _ := status.Lag()
+	mb.logger.Info("레플리카 모드로 백업합니다",
+		"source", host, "file", file, "pos", pos, "lag", lag, "sql_thread_stopped", g.stoppedSQLThread)
+
+	consistency := "SQL 스레드 정지로 고정됨"
+	if !g.stoppedSQLThread {
+		consistency = "백업 시작 시점 (복제가 계속 진행되므로 근사값)"
+	}
+	info := fmt.Sprintf("-- 레플리카 백업: 소스 %s, 위치 기준: %s\n", host, consistency) +
+		fmt.Sprintf("-- GOBACK-SOURCE-POSITION: SOURCE_LOG_FILE='%s', SOURCE_LOG_POS=%s\n", escapeSQLString(file), pos)
+	if gtid != "" {
+		info += fmt.Sprintf("-- GOBACK-SOURCE-GTID: %s\n", gtid)
+	}
+	return g, info, nil
+}
+
+// Stop 이 백업이 멈춘 SQL 스레드를 다시 시작합니다
+// 백업이 실패하거나 취소되어도 호출되며, 원래 컨텍스트와 관계없이 시간 제한 안에서 실행합니다
+func (g *ReplicaGuard) Stop() {
+	if g == nil || !g.stoppedSQLThread {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), replicaRestartTimeout)
+	defer cancel()
+
+	start := "START REPLICA SQL_THREAD"
+	if g.legacy {
+		start = "START SLAVE SQL_THREAD"
+	}
+	if _, err := g.db.ExecContext(ctx, start); err != nil {
+		g.logger.Error("복제 SQL 스레드를 다시 시작하지 못했습니다. 수동으로 시작해야 합니다", "statement", start, "error", err)
+		return
+	}
+	g.stoppedSQLThread = false
+	g.logger.Info("복제 SQL 스레드를 다시 시작했습니다")
+}
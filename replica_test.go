@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replicaRow 컬럼 이름과 값을 번갈아 받아 조회 결과 한 행처럼 만듭니다 ("<NULL>"은 NULL)
+func replicaRow(pairs ...string) ReplicaStatus {
+	var columns []string
+	var values []sql.NullString
+	for i := 0; i+1 < len(pairs); i += 2 {
+		columns = append(columns, pairs[i])
+		if pairs[i+1] == "<NULL>" {
+			values = append(values, sql.NullString{})
+		} else {
+			values = append(values, sql.NullString{String: pairs[i+1], Valid: true})
+		}
+	}
+	return parseReplicaStatus(columns, values)
+}
+
+func TestParseReplicaStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		row       ReplicaStatus
+		lag       time.Duration
+		lagKnown  bool
+		sqlThread bool
+		host      string
+		file      string
+		pos       string
+		gtid      string
+	}{
+		{
+			name: "SHOW REPLICA STATUS",
+			row: replicaRow(
+				"Source_Host", "primary.db",
+				"Replica_SQL_Running", "Yes",
+				"Seconds_Behind_Source", "12",
+				"Relay_Source_Log_File", "binlog.000123",
+				"Exec_Source_Log_Pos", "4567",
+				"Executed_Gtid_Set", "uuid-a:1-100,\nuuid-b:1-5",
+			),
+			lag: 12 * time.Second, lagKnown: true, sqlThread: true,
+			host: "primary.db", file: "binlog.000123", pos: "4567", gtid: "uuid-a:1-100,uuid-b:1-5",
+		},
+		{
+			name: "SHOW SLAVE STATUS",
+			row: replicaRow(
+				"Master_Host", "old-primary",
+				"Slave_SQL_Running", "Yes",
+				"Seconds_Behind_Master", "0",
+				"Relay_Master_Log_File", "mysql-bin.000007",
+				"Exec_Master_Log_Pos", "154",
+				"Executed_Gtid_Set", "",
+			),
+			lag: 0, lagKnown: true, sqlThread: true,
+			host: "old-primary", file: "mysql-bin.000007", pos: "154",
+		},
+		{
+			name: "SQL 스레드 정지",
+			row: replicaRow(
+				"Source_Host", "primary.db",
+				"Replica_SQL_Running", "No",
+				"Seconds_Behind_Source", "<NULL>",
+			),
+			lagKnown: false, sqlThread: false, host: "primary.db",
+		},
+		{
+			name:     "숫자가 아닌 지연",
+			row:      replicaRow("Seconds_Behind_Master", "abc", "Slave_SQL_Running", "Connecting"),
+			lagKnown: false, sqlThread: false,
+		},
+		{
+			name: "빈 행",
+			row:  replicaRow(),
+		},
+	}
+	for _, tt := range tests {
+		lag, ok := tt.row.Lag()
+		if lag != tt.lag || ok != tt.lagKnown {
+			t.Errorf("%s: Lag() = %s, %v, want %s, %v", tt.name, lag, ok, tt.lag, tt.lagKnown)
+		}
+		if got := tt.row.SQLThreadRunning(); got != tt.sqlThread {
+			t.Errorf("%s: SQLThreadRunning() = %v, want %v", tt.name, got, tt.sqlThread)
+		}
+		host, file, pos, gtid := tt.row.Coordinates()
+		if host != tt.host || file != tt.file || pos != tt.pos || gtid != tt.gtid {
+			t.Errorf("%s: Coordinates() = %q %q %q %q, want %q %q %q %q",
+				tt.name, host, file, pos, gtid, tt.host, tt.file, tt.pos, tt.gtid)
+		}
+	}
+}
+
+func TestParseReplicaStatusShortRow(t *testing.T) {
+	// 값이 컬럼보다 적어도 패닉 없이 없는 컬럼으로 처리
+	status := parseReplicaStatus([]string{"Source_Host", "Seconds_Behind_Source"},
+		[]sql.NullString{{String: "primary.db", Valid: true}})
+	if _, ok := status.Lag(); ok {
+		t.Error("Lag() known for a missing column")
+	}
+}
+
+// scriptedStatus 호출할 때마다 다음 상태를 돌려주고, 다 쓰면 마지막 상태를 반복합니다
+func scriptedStatus(rows ...ReplicaStatus) (func(context.Context) (ReplicaStatus, error), *int) {
+	calls := 0
+	return func(context.Context) (ReplicaStatus, error) {
+		row := rows[min(calls, len(rows)-1)]
+		calls++
+		return row, nil
+	}, &calls
+}
+
+func TestReplicaGuardWaitForLag(t *testing.T) {
+	behind := replicaRow("Seconds_Behind_Source", "300")
+	caughtUp := replicaRow("Seconds_Behind_Source", "3")
+	stopped := replicaRow("Seconds_Behind_Source", "<NULL>")
+
+	tests := []struct {
+		name    string
+		rows    []ReplicaStatus
+		maxWait time.Duration
+		calls   int  // 0이면 확인하지 않음
+		wantErr bool // 기다리다 포기
+	}{
+		{name: "허용 범위", rows: []ReplicaStatus{caughtUp}, calls: 1},
+		{name: "지연을 알 수 없음", rows: []ReplicaStatus{stopped}, calls: 1},
+		{name: "따라잡을 때까지 대기", rows: []ReplicaStatus{behind, behind, caughtUp}, calls: 3},
+		{name: "대기 시간 초과", rows: []ReplicaStatus{behind}, maxWait: 20 * time.Millisecond, wantErr: true},
+	}
+	for _, tt := range tests {
+		status, calls := scriptedStatus(tt.rows...)
+		g := &ReplicaGuard{
+			logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+			maxLag:   60 * time.Second,
+			interval: time.Millisecond,
+			maxWait:  tt.maxWait,
+			status:   status,
+		}
+		err := g.WaitForLag(context.Background(), "users")
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "내려오지 않았습니다") {
+				t.Errorf("%s: error = %v, want give-up error", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if tt.calls > 0 && *calls != tt.calls {
+			t.Errorf("%s: status queried %d times, want %d", tt.name, *calls, tt.calls)
+		}
+	}
+}
+
+func TestReplicaGuardWaitForLagSkips(t *testing.T) {
+	status, calls := scriptedStatus(replicaRow("Seconds_Behind_Source", "300"))
+	ctx := context.Background()
+
+	// SQL 스레드를 멈췄으면 데이터가 고정되어 있으므로 조회하지 않음
+	g := &ReplicaGuard{stoppedSQLThread: true, status: status}
+	if err := g.WaitForLag(ctx, "users"); err != nil || *calls != 0 {
+		t.Errorf("stopped SQL thread: err %v, %d queries", err, *calls)
+	}
+
+	// 확인 주기 안에서는 다시 조회하지 않음
+	g = &ReplicaGuard{interval: time.Hour, lastCheck: time.Now(), status: status}
+	if err := g.WaitForLag(ctx, "users"); err != nil || *calls != 0 {
+		t.Errorf("within interval: err %v, %d queries", err, *calls)
+	}
+
+	var nilGuard *ReplicaGuard
+	if err := nilGuard.WaitForLag(ctx, "users"); err != nil {
+		t.Errorf("nil guard: %v", err)
+	}
+}
+
+func TestReplicaGuardWaitForLagCancel(t *testing.T) {
+	status, _ := scriptedStatus(replicaRow("Seconds_Behind_Source", "300"))
+	g := &ReplicaGuard{
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		maxLag:   time.Second,
+		interval: time.Hour,
+		status:   status,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := g.WaitForLag(ctx, "users"); err != context.Canceled {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
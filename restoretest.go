@@ -0,0 +1,554 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// 백업의 주석 줄에서 복원 결과와 비교할 기록을 읽는 패턴
+var (
+	rowCountCommentPattern = regexp.MustCompile(`^-- 테이블 (.+?)(?: 파티션 \S+)?: (\d+) 행$`)
+	formatCommentPattern   = regexp.MustCompile(`^-- 출력 형식: (\S+)$`)
+	maskingCommentPattern  = regexp.MustCompile(`^-- 마스킹 규칙: (\d+)개$`)
+	statusCommentPattern   = regexp.MustCompile(`^-- GOBACK-STATUS: (\S+)`)
+	missingCommentPattern  = regexp.MustCompile(`^-- GOBACK-MISSING-TABLE: (\S+)`)
+)
+
+// skippedStatements 임시 데이터베이스에 복원할 때 실행하지 않는 문장
+// 원래 데이터베이스를 만들거나 고르는 문장과, 테스트 서버에 실제 계정을 만드는 계정/권한 문장입니다
+var skippedStatements = []string{
+	"CREATE DATABASE ", "USE ",
+	"CREATE USER ", "CREATE ROLE ", "ALTER USER ", "GRANT ", "SET DEFAULT ROLE ",
+}
+
+// RestoreTestTable 테이블 하나의 복원 검증 결과
+type RestoreTestTable struct {
+	Table        string          `json:"table"`
+	Passed       bool            `json:"passed"`
+	ExpectedRows int64           `json:"expected_rows"`
+	RestoredRows int64           `json:"restored_rows"`
+	Checksum     string          `json:"checksum"` // match, mismatch, missing (체크섬이 빠진 부분이 있음), none (체크섬 없는 백업), masked (마스킹한 백업이라 비교 안 함)
+	Mismatches   []ChunkMismatch `json:"mismatches,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// RestoreTestReport restore-test 실행 결과 (-report로 JSON 파일에 저장해 스케줄러나 알림에서 사용)
+type RestoreTestReport struct {
+	Backup         string             `json:"backup"`
+	Server         string             `json:"server"`
+	Database       string             `json:"database"` // 복원한 임시 데이터베이스
+	StartedAt      time.Time          `json:"started_at"`
+	FinishedAt     time.Time          `json:"finished_at"`
+	RestoreSeconds float64            `json:"restore_seconds"`
+	Status         string             `json:"status"` // pass, fail
+	Error          string             `json:"error,omitempty"`
+	BackupStatus   string             `json:"backup_status"` // 백업 끝의 GOBACK-STATUS (COMPLETE, INCOMPLETE)
+	Statements     int                `json:"statements"`
+	Skipped        int                `json:"skipped"` // 실행하지 않은 데이터베이스/계정 문장
+	Tables         []RestoreTestTable `json:"tables"`
+	Dropped        bool               `json:"dropped"`
+}
+
+// sqlScript SQL 스크립트를 문장 단위로 읽습니다 (문자열과 식별자 안의 ;는 문장 끝으로 보지 않음)
+// 한 줄 주석은 문장에서 빼고 onComment로 넘깁니다
+type sqlScript struct {
+	r         *bufio.Reader
+	onComment func(line string)
+}
+
+func newSQLScript(r io.Reader, onComment func(line string)) *sqlScript {
+	return &sqlScript{r: bufio.NewReaderSize(r, 1024*1024), onComment: onComment}
+}
+
+// Next 다음 문장을 반환합니다 (끝에 ; 없음, 더 없으면 io.EOF)
+func (s *sqlScript) Next() (string, error) {
+	var stmt []byte
+	var quote byte
+	for {
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			if rest := strings.TrimSpace(string(stmt)); rest != "" {
+				return rest, nil
+			}
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", err
+		}
+
+		// 문자열, 식별자 안 (백틱 식별자가 아니면 백슬래시 이스케이프)
+		if quote != 0 {
+			stmt = append(stmt, c)
+			if c == '\\' && quote != '`' {
+				next, err := s.r.ReadByte()
+				if err != nil {
+					return "", fmt.Errorf("따옴표가 닫히지 않은 문장: %v", err)
+				}
+				stmt = append(stmt, next)
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			stmt = append(stmt, c)
+		case c == '/' && s.peek('*'):
+			// 블록 주석 (/*!50100 PARTITION BY ... */ 같은 버전 주석은 문장의 일부로 남김)
+			stmt = append(stmt, c)
+			for prev := byte(0); ; {
+				next, err := s.r.ReadByte()
+				if err != nil {
+					return "", fmt.Errorf("닫히지 않은 블록 주석: %v", err)
+				}
+				stmt = append(stmt, next)
+				if prev == '*' && next == '/' && len(stmt) > 3 {
+					break
+				}
+				prev = next
+			}
+		case c == ';':
+			if text := strings.TrimSpace(string(stmt)); text != "" {
+				return text, nil
+			}
+			stmt = stmt[:0]
+		case c == '#' || (c == '-' && s.lineComment()):
+			rest, err := s.r.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return "", err
+			}
+			line := strings.TrimRight(string(c)+rest, "\r\n")
+			if s.onComment != nil {
+				s.onComment(line)
+			}
+			stmt = append(stmt, '\n')
+		default:
+			stmt = append(stmt, c)
+		}
+	}
+}
+
+// peek 다음 바이트가 b인지 확인합니다
+func (s *sqlScript) peek(b byte) bool {
+	next, _ := s.r.Peek(1)
+	return len(next) == 1 && next[0] == b
+}
+
+// lineComment -를 읽은 뒤 "-- " 한 줄 주석이 시작되는지 확인합니다 (두 번째 -와 공백 또는 줄 끝)
+func (s *sqlScript) lineComment() bool {
+	next, err := s.r.Peek(2)
+	if len(next) == 0 || next[0] != '-' {
+		return false
+	}
+	return len(next) == 1 && err == io.EOF || next[1] == ' ' || next[1] == '\t' || next[1] == '\n' || next[1] == '\r'
+}
+
+// restoreRun 백업 하나를 임시 데이터베이스에 복원하면서 백업에 기록된 행 수와 체크섬을 모읍니다
+type restoreRun struct {
+	conn   *sql.Conn // SET 같은 세션 상태가 유지되도록 모든 문장을 연결 하나로 실행
+	report *RestoreTestReport
+
+	tables    []string         // 행 수가 기록된 테이블 (백업 순서)
+	rowCounts map[string]int64 // 백업에 기록된 테이블별 행 수 (파티션별로 기록된 테이블은 합계)
+	checksums map[string]TableChecksum
+	missing   []string // 백업에서 빠진 테이블 (불완전한 백업)
+	masked    bool
+	err       error // 주석 줄을 읽다 생긴 오류
+}
+
+// comment 백업의 주석 줄에서 행 수, 완료 표시, 누락 테이블, 체크섬을 기록합니다
+func (r *restoreRun) comment(line string) {
+	if m := rowCountCommentPattern.FindStringSubmatch(line); m != nil {
+		count, _ := strconv.ParseInt(m[2], 10, 64)
+		if _, ok := r.rowCounts[m[1]]; !ok {
+			r.tables = append(r.tables, m[1])
+		}
+		r.rowCounts[m[1]] += count
+	} else if m := maskingCommentPattern.FindStringSubmatch(line); m != nil {
+		r.masked = m[1] != "0"
+	} else if m := statusCommentPattern.FindStringSubmatch(line); m != nil {
+		r.report.BackupStatus = m[1]
+	} else if m := missingCommentPattern.FindStringSubmatch(line); m != nil {
+		r.missing = append(r.missing, m[1])
+	} else if table, ok, err := parseChecksumLine(line); err != nil {
+		r.err = err
+	} else if ok {
+		r.checksums[table.Table] = table
+	}
+}
+
+// runScript 스크립트의 문장을 차례로 실행합니다 (SOURCE는 스크립트 위치 기준으로 이어서 실행)
+func (r *restoreRun) runScript(ctx context.Context, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	script := newSQLScript(file, r.comment)
+	for {
+		stmt, err := script.Next()
+		if err == io.EOF {
+			return r.err
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if err := r.exec(ctx, filepath.Dir(path), stmt); err != nil {
+			return fmt.Errorf("%s: %v (문장: %s)", filepath.Base(path), err, statementPreview(stmt))
+		}
+	}
+}
+
+// exec 문장 하나를 실행합니다
+// 데이터베이스/계정 문장은 건너뛰고, LOAD DATA LOCAL INFILE은 스크립트 옆의 데이터 파일을 읽도록 절대 경로로 바꿉니다
+func (r *restoreRun) exec(ctx context.Context, dir, stmt string) error {
+	if name, ok := cutKeyword(stmt, "SOURCE "); ok {
+		if filepath.Base(name) == accountsFileName {
+			r.report.Skipped++
+			return nil
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		return r.runScript(ctx, name)
+	}
+	for _, keyword := range skippedStatements {
+		if _, ok := cutKeyword(stmt, keyword); ok {
+			r.report.Skipped++
+			return nil
+		}
+	}
+
+	if rest, ok := cutKeyword(stmt, loadDataLocalInfile); ok {
+		name, tail, ok := cutQuoted(rest)
+		if !ok {
+			return fmt.Errorf("LOAD DATA 파일 이름을 읽을 수 없습니다")
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return err
+		}
+		// 드라이버는 등록한 파일만 서버로 보냄
+		mysql.RegisterLocalFile(path)
+		defer mysql.DeregisterLocalFile(path)
+		stmt = loadDataLocalInfile + "'" + escapeSQLString(path) + "'" + tail
+	}
+
+	if _, err := r.conn.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+	r.report.Statements++
+	return nil
+}
+
+const loadDataLocalInfile = "LOAD DATA LOCAL INFILE "
+
+// cutKeyword 문장이 keyword로 시작하면 (대소문자 무시) 나머지를 반환합니다
+func cutKeyword(stmt, keyword string) (string, bool) {
+	if len(stmt) < len(keyword) || !strings.EqualFold(stmt[:len(keyword)], keyword) {
+		return "", false
+	}
+	return strings.TrimSpace(stmt[len(keyword):]), true
+}
+
+// sqlStringUnescapes escapeSQLString이 바꾼 문자를 되돌리는 백슬래시 이스케이프 (나머지는 백슬래시만 뗌)
+var sqlStringUnescapes = map[byte]byte{'0': '\x00', 'n': '\n', 'r': '\r', 't': '\t', 'b': '\b', 'Z': '\x1a'}
+
+// cutQuoted 작은따옴표 문자열 리터럴을 읽어 값과 나머지를 반환합니다
+func cutQuoted(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, "'") {
+		return "", "", false
+	}
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			if unescaped, ok := sqlStringUnescapes[s[i]]; ok {
+				value.WriteByte(unescaped)
+			} else {
+				value.WriteByte(s[i])
+			}
+		case c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+			value.WriteByte('\'')
+		case c == '\'':
+			return value.String(), s[i+1:], true
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// statementPreview 오류 메시지에 넣을 문장 앞부분
+func statementPreview(stmt string) string {
+	stmt = strings.Join(strings.Fields(stmt), " ")
+	if len(stmt) > 120 {
+		return stmt[:120] + "..."
+	}
+	return stmt
+}
+
+// backupFormat 백업 헤더의 출력 형식 (디렉토리 형식은 load.sql 헤더)
+func backupFormat(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for i := 0; i < 50 && scanner.Scan(); i++ {
+		if m := formatCommentPattern.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("goback 백업 헤더가 없습니다")
+}
+
+// verify 복원한 데이터베이스의 테이블마다 행 수와 청크 체크섬을 백업의 기록과 비교합니다
+func (r *restoreRun) verify(ctx context.Context, db *sql.DB) error {
+	for _, name := range r.missing {
+		r.report.Tables = append(r.report.Tables, RestoreTestTable{Table: name, Checksum: "none", Error: "백업에서 빠진 테이블"})
+	}
+	for _, name := range r.tables {
+		result := RestoreTestTable{Table: name, ExpectedRows: r.rowCounts[name], Checksum: "none"}
+		err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM `%s`", name)).Scan(&result.RestoredRows)
+		if err == nil {
+			if table, ok := r.checksums[name]; ok && r.masked {
+				// 체크섬은 마스킹 전 원본 값으로 계산했으므로 복원한 값과 같을 수 없음
+				result.Checksum = "masked"
+			} else if ok && table.Missing {
+				// 체크섬이 빠진 범위는 검증할 수 없으므로 통과로 보지 않음
+				result.Checksum = "missing"
+			} else if ok {
+				result.Mismatches, err = checkTable(ctx, db, table)
+				result.Checksum = "match"
+				if len(result.Mismatches) > 0 {
+					result.Checksum = "mismatch"
+				}
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			result.Error = err.Error()
+		}
+		result.Passed = result.Error == "" && result.RestoredRows == result.ExpectedRows && result.Checksum != "mismatch" && result.Checksum != "missing"
+		r.report.Tables = append(r.report.Tables, result)
+	}
+	return nil
+}
+
+// restoreTarget 복원할 서버 설정 (RESTORE_TEST_*가 없으면 MYSQL_* 설정과 같은 서버)
+func restoreTarget(config *BackupConfig, database string) BackupConfig {
+	target := *config
+	target.Database = database
+	if config.RestoreHost != "" {
+		target.Host = config.RestoreHost
+	}
+	if config.RestorePort != "" {
+		target.Port = config.RestorePort
+	}
+	if config.RestoreUsername != "" {
+		target.Username = config.RestoreUsername
+	}
+	if config.RestorePassword != "" {
+		target.Password = config.RestorePassword
+	}
+	return target
+}
+
+// runRestoreTest restore-test 하위 명령: 백업을 임시 데이터베이스에 복원하고 테이블별 행 수와 체크섬을 백업의 기록과 비교한 뒤 임시 데이터베이스를 지웁니다
+// 종료 코드는 통과하면 0, 검증에 실패하면 exitCodeDifferent, 복원하지 못하면 exitCodeFailure입니다
+func runRestoreTest(ctx context.Context, config *BackupConfig, args []string) int {
+	flags := flag.NewFlagSet("restore-test", flag.ExitOnError)
+	database := flags.String("database", "", "복원할 임시 데이터베이스 이름 (기본값: goback_restore_<시각>, 이미 있으면 실패)")
+	keep := flags.Bool("keep", false, "검증 후 임시 데이터베이스를 지우지 않고 남김")
+	reportPath := flags.String("report", "", "결과를 JSON 보고서로 저장할 파일")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "사용법: %s restore-test [-database 이름] [-keep] [-report 파일] <백업>\n", filepath.Base(os.Args[0]))
+		fmt.Fprintln(flags.Output(), "  <백업>: sql, csv, tsv 형식의 goback 백업 파일(.sql)이나 디렉토리")
+		fmt.Fprintln(flags.Output(), "  복원할 서버는 RESTORE_TEST_* 설정 (없으면 MYSQL_* 설정과 같은 서버)")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitCodeFailure
+	}
+	if *database == "" {
+		*database = "goback_restore_" + time.Now().Format("20060102_150405")
+	}
+
+	target := restoreTarget(config, *database)
+	report := &RestoreTestReport{
+		Backup:    flags.Arg(0),
+		Server:    target.Host + ":" + target.Port,
+		Database:  *database,
+		StartedAt: time.Now(),
+		Status:    "fail",
+	}
+	code := restoreTest(ctx, config, target, report, *keep)
+	report.FinishedAt = time.Now()
+	if code == 0 {
+		report.Status = "pass"
+	}
+
+	printRestoreReport(report)
+	if *reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = os.WriteFile(*reportPath, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			slog.Error("보고서 저장 실패", "file", *reportPath, "error", err)
+			return exitCodeFailure
+		}
+	}
+	return code
+}
+
+// restoreTest 복원과 검증을 실행하고 종료 코드를 반환합니다 (오류는 보고서에 기록)
+func restoreTest(ctx context.Context, config *BackupConfig, target BackupConfig, report *RestoreTestReport, keep bool) int {
+	fail := func(err error) int {
+		report.Error = err.Error()
+		if ctx.Err() != nil {
+			return exitCodeInterrupted
+		}
+		return exitCodeFailure
+	}
+
+	script := report.Backup
+	if info, err := os.Stat(script); err != nil {
+		return fail(err)
+	} else if info.IsDir() {
+		script = filepath.Join(script, "load.sql")
+	}
+	format, err := backupFormat(script)
+	if err != nil {
+		return fail(fmt.Errorf("%s: %v", script, err))
+	}
+	if !restorableFormat(format) {
+		return fail(fmt.Errorf("%s 형식 백업은 복원할 수 없습니다 (sql, csv, tsv만 지원)", format))
+	}
+
+	// 데이터베이스 없이 접속해 임시 데이터베이스를 새로 만듦 (이미 있으면 다른 데이터를 지우지 않도록 실패)
+	server := target
+	server.Database = ""
+	mb := NewMySQLBackup(&server)
+	if err := mb.Connect(ctx); err != nil {
+		return fail(err)
+	}
+	defer mb.Close()
+	if _, err := mb.db.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE `%s`", report.Database)); err != nil {
+		return fail(fmt.Errorf("임시 데이터베이스 생성 실패: %v", err))
+	}
+	if !keep {
+		defer func() {
+			// 중단되어도 지우도록 취소되지 않는 컨텍스트 사용
+			if _, err := mb.db.ExecContext(context.Background(), fmt.Sprintf("DROP DATABASE `%s`", report.Database)); err != nil {
+				slog.Error("임시 데이터베이스 삭제 실패", "database", report.Database, "error", err)
+				return
+			}
+			report.Dropped = true
+		}()
+	}
+
+	conn, err := mb.db.Conn(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("USE `%s`", report.Database)); err != nil {
+		return fail(err)
+	}
+
+	run := &restoreRun{
+		conn:      conn,
+		report:    report,
+		rowCounts: make(map[string]int64),
+		checksums: make(map[string]TableChecksum),
+	}
+	slog.Info("백업을 임시 데이터베이스에 복원합니다", "backup", report.Backup, "server", report.Server, "database", report.Database)
+	restoreStart := time.Now()
+	err = run.runScript(ctx, script)
+	report.RestoreSeconds = time.Since(restoreStart).Seconds()
+	if err != nil {
+		return fail(fmt.Errorf("복원 실패: %v", err))
+	}
+	if report.BackupStatus == "" {
+		return fail(fmt.Errorf("GOBACK-STATUS 완료 표시가 없는 잘린 백업입니다"))
+	}
+
+	// 체크섬은 백업할 때와 같은 연결 설정(utf8mb4, UTC)으로 계산
+	restored := NewMySQLBackup(&target)
+	if err := restored.Connect(ctx); err != nil {
+		return fail(err)
+	}
+	defer restored.Close()
+	if err := run.verify(ctx, restored.db); err != nil {
+		return fail(err)
+	}
+
+	for _, table := range report.Tables {
+		if !table.Passed {
+			return exitCodeDifferent
+		}
+	}
+	if report.BackupStatus != "COMPLETE" {
+		return exitCodeDifferent
+	}
+	return 0
+}
+
+// printRestoreReport 결과를 사람이 읽을 수 있게 출력합니다
+func printRestoreReport(report *RestoreTestReport) {
+	fmt.Printf("복원 테스트: %s → %s/%s\n", report.Backup, report.Server, report.Database)
+	passed := 0
+	for _, table := range report.Tables {
+		if table.Passed {
+			passed++
+			continue
+		}
+		switch {
+		case table.Error != "":
+			fmt.Printf("✗ 테이블 `%s`: %s\n", table.Table, table.Error)
+		case table.RestoredRows != table.ExpectedRows:
+			fmt.Printf("✗ 테이블 `%s`: 행 %d → %d\n", table.Table, table.ExpectedRows, table.RestoredRows)
+		}
+		for _, mismatch := range table.Mismatches {
+			fmt.Printf("✗ 테이블 `%s` %s\n", table.Table, mismatch)
+		}
+	}
+	if report.Error != "" {
+		fmt.Printf("✗ %s\n", report.Error)
+	}
+	fmt.Printf("요약: %s, 테이블 %d개 중 %d개 통과, 문장 %d개 실행 (%d개 건너뜀), 복원 %.1f초\n",
+		strings.ToUpper(report.Status), len(report.Tables), passed, report.Statements, report.Skipped, report.RestoreSeconds)
+}
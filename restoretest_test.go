@@ -0,0 +1,138 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func readStatements(t *testing.T, script string) ([]string, []string) {
+	t.Helper()
+	var comments []string
+	s := newSQLScript(strings.NewReader(script), func(line string) { comments = append(comments, line) })
+	var statements []string
+	for {
+		stmt, err := s.Next()
+		if err == io.EOF {
+			return statements, comments
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		statements = append(statements, stmt)
+	}
+}
+
+func TestSQLScriptNext(t *testing.T) {
+	script := "-- 테이블 users: 2 행\n" +
+		"SET NAMES utf8mb4;\n" +
+		"INSERT INTO `users` VALUES (1,'a;b','it''s','back\\\\slash\\';'),(2,\"x;y\",NULL);\n" +
+		"CREATE TABLE `we;ird``name` (\n" +
+		"  `id` int -- 끝에 붙은 주석; 문장 끝 아님\n" +
+		") /*!50100 PARTITION BY HASH (`id`); */;\n" +
+		"# 해시 주석; 무시\n" +
+		";;\n" +
+		"SELECT 1--1;\n" +
+		"SELECT '-- 주석 아님', 2 /* 블록; 주석 */;\n" +
+		"SELECT 3"
+	statements, comments := readStatements(t, script)
+
+	want := []string{
+		"SET NAMES utf8mb4",
+		"INSERT INTO `users` VALUES (1,'a;b','it''s','back\\\\slash\\';'),(2,\"x;y\",NULL)",
+		"CREATE TABLE `we;ird``name` (\n  `id` int \n) /*!50100 PARTITION BY HASH (`id`); */",
+		"SELECT 1--1",
+		"SELECT '-- 주석 아님', 2 /* 블록; 주석 */",
+		"SELECT 3",
+	}
+	if len(statements) != len(want) {
+		t.Fatalf("got %d statements %q, want %d", len(statements), statements, len(want))
+	}
+	for i := range want {
+		if statements[i] != want[i] {
+			t.Errorf("statement %d = %q, want %q", i, statements[i], want[i])
+		}
+	}
+
+	wantComments := []string{"-- 테이블 users: 2 행", "-- 끝에 붙은 주석; 문장 끝 아님", "# 해시 주석; 무시"}
+	if strings.Join(comments, "\n") != strings.Join(wantComments, "\n") {
+		t.Errorf("comments = %q, want %q", comments, wantComments)
+	}
+}
+
+func TestSQLScriptUnterminated(t *testing.T) {
+	for _, script := range []string{"SELECT 'abc\\", "SELECT 1 /* never closed"} {
+		s := newSQLScript(strings.NewReader(script), nil)
+		if _, err := s.Next(); err == nil || err == io.EOF {
+			t.Errorf("Next(%q) error = %v, want unterminated error", script, err)
+		}
+	}
+	// 닫히지 않은 따옴표는 남은 내용을 마지막 문장으로 반환 (서버가 문법 오류로 알려줌)
+	if statements, _ := readStatements(t, "SELECT 'abc;\n"); len(statements) != 1 {
+		t.Errorf("statements = %q", statements)
+	}
+	if statements, comments := readStatements(t, "--\n-- done"); len(statements) != 0 || len(comments) != 2 {
+		t.Errorf("comment-only script: statements %q, comments %q", statements, comments)
+	}
+}
+
+func TestCutQuoted(t *testing.T) {
+	tests := []struct {
+		in, value, rest string
+		ok              bool
+	}{
+		{"'00001_users.tsv' INTO TABLE `users`", "00001_users.tsv", " INTO TABLE `users`", true},
+		{"'it''s.tsv' X", "it's.tsv", " X", true},
+		{`'a\'b\\c' X`, `a'b\c`, " X", true},
+		{"'' X", "", " X", true},
+		{"'unterminated", "", "", false},
+		{"noquote", "", "", false},
+	}
+	for _, tt := range tests {
+		value, rest, ok := cutQuoted(tt.in)
+		if value != tt.value || rest != tt.rest || ok != tt.ok {
+			t.Errorf("cutQuoted(%q) = %q, %q, %v, want %q, %q, %v", tt.in, value, rest, ok, tt.value, tt.rest, tt.ok)
+		}
+	}
+
+	// loadDataSQL이 이스케이프한 파일 이름을 그대로 되돌려야 함
+	for _, name := range []string{"plain.tsv", "it's.tsv", `back\slash.tsv`, "new\nline\r.tsv", "nul\x00ctrl\x1a.tsv"} {
+		stmt := loadDataSQL(FormatTSV, name, "t", nil)
+		rest, ok := cutKeyword(stmt, loadDataLocalInfile)
+		if !ok {
+			t.Fatalf("cutKeyword(%q) failed", stmt)
+		}
+		if value, tail, ok := cutQuoted(rest); !ok || value != name || !strings.HasPrefix(tail, " INTO TABLE `t`") {
+			t.Errorf("round trip of %q = %q, %q, %v", name, value, tail, ok)
+		}
+	}
+}
+
+func TestCutKeyword(t *testing.T) {
+	if rest, ok := cutKeyword("source  00001_users.sql", "SOURCE "); !ok || rest != "00001_users.sql" {
+		t.Errorf("cutKeyword = %q, %v", rest, ok)
+	}
+	if _, ok := cutKeyword("USER_ID = 1", "USE "); ok {
+		t.Error("cutKeyword matched a longer word")
+	}
+	if _, ok := cutKeyword("USE", "USE "); ok {
+		t.Error("cutKeyword matched a shorter statement")
+	}
+}
+
+func TestRowCountCommentPattern(t *testing.T) {
+	tests := map[string][]string{
+		"-- 테이블 users: 12 행":           {"users", "12"},
+		"-- 테이블 events 파티션 p2024: 5 행": {"events", "5"},
+		"-- 테이블 my table: 0 행":         {"my table", "0"},
+	}
+	for line, want := range tests {
+		m := rowCountCommentPattern.FindStringSubmatch(line)
+		if m == nil || m[1] != want[0] || m[2] != want[1] {
+			t.Errorf("rowCountCommentPattern(%q) = %q, want %q", line, m, want)
+		}
+	}
+	if rowCountCommentPattern.MatchString("-- 테이블 users: 많음 행") {
+		t.Error("matched a non-numeric count")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// retryableMySQLErrors 재시도하면 성공할 가능성이 있는 일시적인 MySQL 서버 오류 코드
+var retryableMySQLErrors = map[uint16]string{
+	1040: "ER_CON_COUNT_ERROR",            // Too many connections
+	1053: "ER_SERVER_SHUTDOWN",            // Server shutdown in progress
+	1158: "ER_NET_READ_ERROR",             // Got an error reading communication packets
+	1159: "ER_NET_READ_INTERRUPTED",       // Got timeout reading communication packets
+	1160: "ER_NET_ERROR_ON_WRITE",         // Got an error writing communication packets
+	1161: "ER_NET_WRITE_INTERRUPTED",      // Got timeout writing communication packets
+	1205: "ER_LOCK_WAIT_TIMEOUT",          // Lock wait timeout exceeded
+	1213: "ER_LOCK_DEADLOCK",              // Deadlock found when trying to get lock
+	1927: "ER_CONNECTION_KILLED",          // Connection was killed (MariaDB)
+	2006: "CR_SERVER_GONE_ERROR",          // MySQL server has gone away
+	2013: "CR_SERVER_LOST",                // Lost connection to MySQL server during query
+	3024: "ER_QUERY_TIMEOUT",              // Query execution was interrupted, maximum statement execution time exceeded
+	4031: "ER_CLIENT_INTERACTION_TIMEOUT", // Disconnected by the server because of inactivity
+}
+
+// isRetryableError 배치를 다시 시도해도 되는 일시적인 오류인지 판단합니다
+// 컨텍스트 취소는 사용자의 의도이므로 재시도하지 않습니다
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		_, ok := retryableMySQLErrors[mysqlErr.Number]
+		return ok
+	}
+
+	// 드라이버 수준의 연결 끊김
+	if errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryBackoff 시도 횟수에 따른 지수 백오프 대기 시간 (최대값 제한, ±20% 지터)
+func retryBackoff(attempt int, initial, max time.Duration) time.Duration {
+	backoff := initial << attempt
+	if backoff <= 0 || backoff > max {
+		backoff = max
+	}
+	jitter := time.Duration(float64(backoff) * (rand.Float64()*0.4 - 0.2))
+	return backoff + jitter
+}
+
+// withRetry 일시적인 오류가 발생하면 지수 백오프로 fn을 다시 실행합니다
+// fn은 실패 시 부작용이 없어야 합니다 (커서 배치는 성공한 경우에만 결과를 반영)
+func (mb *MySQLBackup) withRetry(ctx context.Context, tableName string, cursor interface{}, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= mb.config.RetryMax || !isRetryableError(err) {
+			return err
+		}
+
+		backoff := retryBackoff(attempt, mb.config.RetryBackoff, mb.config.RetryMaxBackoff)
+		mb.logger.Warn("일시적인 오류로 배치를 재시도합니다",
+			"table", tableName,
+			"attempt", attempt+1,
+			"max_attempts", mb.config.RetryMax,
+			"backoff", backoff,
+			"cursor", cursor,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
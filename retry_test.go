@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"deadlock", &mysql.MySQLError{Number: 1213}, true},
+		{"lock wait timeout", &mysql.MySQLError{Number: 1205}, true},
+		{"server gone away", &mysql.MySQLError{Number: 2006}, true},
+		{"wrapped mysql error", fmt.Errorf("배치 실패: %w", &mysql.MySQLError{Number: 2013}), true},
+		{"syntax error", &mysql.MySQLError{Number: 1064}, false},
+		{"unknown table", &mysql.MySQLError{Number: 1146}, false},
+		{"invalid connection", mysql.ErrInvalidConn, true},
+		{"bad connection", driver.ErrBadConn, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"network error", &net.OpError{Op: "read", Err: errors.New("connection reset")}, true},
+		{"canceled", context.Canceled, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"canceled wrapping retryable", fmt.Errorf("%w: %w", context.Canceled, mysql.ErrInvalidConn), false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffBounds(t *testing.T) {
+	initial, max := time.Second, 30*time.Second
+	for attempt := 0; attempt < 100; attempt++ {
+		base := max
+		if attempt < 5 {
+			base = initial << attempt
+		}
+		low, high := time.Duration(float64(base)*0.8), time.Duration(float64(base)*1.2)
+		for i := 0; i < 20; i++ {
+			got := retryBackoff(attempt, initial, max)
+			if got < low || got > high {
+				t.Fatalf("retryBackoff(%d) = %v, want between %v and %v", attempt, got, low, high)
+			}
+		}
+	}
+}
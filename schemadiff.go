@@ -0,0 +1,515 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// schemaItem 컬럼, 인덱스, 외래 키, CHECK 제약 조건 하나 (SHOW CREATE TABLE의 한 줄)
+type schemaItem struct {
+	Name       string
+	Definition string
+}
+
+// tableSchema 비교할 수 있도록 나눈 CREATE TABLE 문
+type tableSchema struct {
+	Name        string
+	Create      string // 원래 CREATE TABLE 문 (새 테이블을 만들 때 사용)
+	Columns     []schemaItem
+	Indexes     []schemaItem // PRIMARY KEY는 이름이 PRIMARY
+	ForeignKeys []schemaItem
+	Checks      []schemaItem
+	Options     string // 닫는 괄호 뒤 테이블 옵션 (AUTO_INCREMENT 값 제외)
+}
+
+var (
+	createTablePattern   = regexp.MustCompile("^CREATE TABLE (?:IF NOT EXISTS )?`((?:[^`]|``)+)`")
+	indexLinePattern     = regexp.MustCompile("^(?:UNIQUE |FULLTEXT |SPATIAL )?KEY `((?:[^`]|``)+)`")
+	constraintPattern    = regexp.MustCompile("^CONSTRAINT `((?:[^`]|``)+)` (FOREIGN KEY|CHECK)")
+	addConstraintPattern = regexp.MustCompile("^ALTER TABLE `((?:[^`]|``)+)` ADD (CONSTRAINT `(?:[^`]|``)+` FOREIGN KEY .*);$")
+	autoIncrementPattern = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+	columnNamePattern    = regexp.MustCompile("^`((?:[^`]|``)+)` ")
+)
+
+// parseCreateTable SHOW CREATE TABLE 형식의 문을 컬럼, 인덱스, 외래 키, CHECK, 테이블 옵션으로 나눕니다
+func parseCreateTable(statement string) (*tableSchema, error) {
+	statement = strings.TrimSuffix(strings.TrimSpace(statement), ";")
+	lines := strings.Split(statement, "\n")
+	match := createTablePattern.FindStringSubmatch(lines[0])
+	if match == nil {
+		return nil, fmt.Errorf("CREATE TABLE 문이 아닙니다: %.60s", lines[0])
+	}
+	table := &tableSchema{Name: strings.ReplaceAll(match[1], "``", "`"), Create: statement}
+
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, ")") {
+			// 파티션 정의처럼 여러 줄에 걸친 옵션도 포함
+			options := strings.TrimSpace(strings.Join(append([]string{line[1:]}, lines[i+2:]...), "\n"))
+			table.Options = autoIncrementPattern.ReplaceAllString(" "+options, "")
+			table.Options = strings.TrimSpace(table.Options)
+			break
+		}
+		line = strings.TrimSuffix(line, ",")
+
+		switch {
+		case strings.HasPrefix(line, "`"):
+			m := columnNamePattern.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("테이블 %s: 컬럼 정의를 읽을 수 없습니다: %s", table.Name, line)
+			}
+			table.Columns = append(table.Columns, schemaItem{Name: strings.ReplaceAll(m[1], "``", "`"), Definition: line})
+		case strings.HasPrefix(line, "PRIMARY KEY"):
+			table.Indexes = append(table.Indexes, schemaItem{Name: "PRIMARY", Definition: line})
+		case indexLinePattern.MatchString(line):
+			m := indexLinePattern.FindStringSubmatch(line)
+			table.Indexes = append(table.Indexes, schemaItem{Name: strings.ReplaceAll(m[1], "``", "`"), Definition: line})
+		case constraintPattern.MatchString(line):
+			m := constraintPattern.FindStringSubmatch(line)
+			item := schemaItem{Name: strings.ReplaceAll(m[1], "``", "`"), Definition: line}
+			if m[2] == "FOREIGN KEY" {
+				item.Definition = normalizeForeignKey(line)
+				table.ForeignKeys = append(table.ForeignKeys, item)
+			} else {
+				table.Checks = append(table.Checks, item)
+			}
+		default:
+			// 이름 없는 CHECK 등은 정의 자체를 이름으로 비교
+			table.Checks = append(table.Checks, schemaItem{Name: line, Definition: line})
+		}
+	}
+	return table, nil
+}
+
+// normalizeForeignKey InnoDB에서 같은 의미인 RESTRICT/NO ACTION 규칙을 빼서
+// SHOW CREATE TABLE(규칙 생략)과 백업 끝의 ALTER TABLE(규칙 명시)을 같은 정의로 비교합니다 (복합 키 컬럼 구분도 맞춤)
+func normalizeForeignKey(definition string) string {
+	definition = strings.ReplaceAll(definition, "`, `", "`,`")
+	for _, rule := range []string{" ON DELETE RESTRICT", " ON DELETE NO ACTION", " ON UPDATE RESTRICT", " ON UPDATE NO ACTION"} {
+		definition = strings.ReplaceAll(definition, rule, "")
+	}
+	return definition
+}
+
+// schemaSource 비교할 스키마 (백업 파일/디렉토리 또는 라이브 데이터베이스)
+type schemaSource struct {
+	Label  string
+	Tables map[string]*tableSchema
+}
+
+// loadSchemaSource 인수에 맞는 스키마를 읽습니다
+// live 또는 live:<데이터베이스>는 라이브 데이터베이스(MYSQL_* 설정), 그 외에는 goback 백업 파일이나 디렉토리입니다
+func loadSchemaSource(ctx context.Context, config *BackupConfig, arg string) (*schemaSource, error) {
+	if arg == "live" || strings.HasPrefix(arg, "live:") {
+		liveConfig := *config
+		if database, ok := strings.CutPrefix(arg, "live:"); ok {
+			liveConfig.Database = database
+		}
+		if liveConfig.Database == "" {
+			return nil, fmt.Errorf("라이브 데이터베이스 이름이 없습니다 (live:<데이터베이스> 또는 MYSQL_DATABASE)")
+		}
+		liveConfig.Workers = 1
+		tables, err := loadLiveSchema(ctx, &liveConfig)
+		if err != nil {
+			return nil, err
+		}
+		return &schemaSource{Label: fmt.Sprintf("%s:%s/%s", liveConfig.Host, liveConfig.Port, liveConfig.Database), Tables: tables}, nil
+	}
+
+	tables, err := loadBackupSchema(arg)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaSource{Label: arg, Tables: tables}, nil
+}
+
+// loadLiveSchema 라이브 데이터베이스의 테이블 구조를 getCreateTableSQL로 읽습니다 (뷰 제외)
+func loadLiveSchema(ctx context.Context, config *BackupConfig) (map[string]*tableSchema, error) {
+	mb := NewMySQLBackup(config)
+	if err := mb.Connect(ctx); err != nil {
+		return nil, err
+	}
+	defer mb.Close()
+
+	names, err := mb.GetTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[string]*tableSchema, len(names))
+	for _, name := range names {
+		createSQL, err := mb.getCreateTableSQL(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("테이블 '%s' 구조 조회 실패: %v", name, err)
+		}
+		if !createTablePattern.MatchString(createSQL) {
+			continue
+		}
+		table, err := parseCreateTable(createSQL)
+		if err != nil {
+			return nil, err
+		}
+		tables[table.Name] = table
+	}
+	return tables, nil
+}
+
+// loadBackupSchema goback 백업의 CREATE TABLE 문을 읽습니다
+// SQL 파일은 파일 하나, 디렉토리 형식은 디렉토리의 모든 .sql 파일(테이블 구조 파일과 load.sql)을 읽고,
+// 순환 참조 때문에 CREATE TABLE에서 빠져 끝에 ALTER TABLE로 추가된 외래 키를 다시 테이블에 붙입니다
+func loadBackupSchema(path string) (map[string]*tableSchema, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.sql")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	tables := make(map[string]*tableSchema)
+	var deferred [][2]string // 테이블, 외래 키 정의
+	for _, file := range files {
+		err := readSchemaStatements(file, func(statement string) error {
+			if strings.HasPrefix(statement, "ALTER TABLE ") {
+				if m := addConstraintPattern.FindStringSubmatch(statement); m != nil {
+					deferred = append(deferred, [2]string{strings.ReplaceAll(m[1], "``", "`"), m[2]})
+				}
+				return nil
+			}
+			table, err := parseCreateTable(statement)
+			if err != nil {
+				return err
+			}
+			tables[table.Name] = table
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+	}
+
+	for _, fk := range deferred {
+		table := tables[fk[0]]
+		if table == nil {
+			continue
+		}
+		m := constraintPattern.FindStringSubmatch(fk[1])
+		table.ForeignKeys = append(table.ForeignKeys, schemaItem{Name: strings.ReplaceAll(m[1], "``", "`"), Definition: normalizeForeignKey(fk[1])})
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("%s: CREATE TABLE 문이 없습니다 (goback 백업이 아닌 파일)", path)
+	}
+	return tables, nil
+}
+
+// readSchemaStatements 파일에서 CREATE TABLE 문과 외래 키를 추가하는 ALTER TABLE 문만 골라 fn에 넘깁니다 (INSERT 등은 건너뜀)
+func readSchemaStatements(path string, fn func(statement string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 1024*1024)
+	var statement strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case statement.Len() > 0:
+			statement.WriteString("\n" + trimmed)
+		case strings.HasPrefix(trimmed, "CREATE TABLE "), strings.HasPrefix(trimmed, "ALTER TABLE "):
+			statement.WriteString(trimmed)
+		}
+		if statement.Len() > 0 && strings.HasSuffix(trimmed, ";") {
+			if err := fn(statement.String()); err != nil {
+				return err
+			}
+			statement.Reset()
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// schemaChange 테이블 안의 변경 하나
+type schemaChange struct {
+	Op    string // + 추가, - 삭제, ~ 변경
+	What  string // 컬럼, 인덱스, 외래 키, CHECK, 테이블 옵션
+	Name  string
+	Old   string
+	New   string
+	After string // 추가된 컬럼의 앞 컬럼 (비어있으면 맨 앞)
+}
+
+// tableDiff 테이블 하나의 비교 결과
+type tableDiff struct {
+	Name    string
+	Op      string // + 추가, - 삭제, ~ 변경
+	From    *tableSchema
+	To      *tableSchema
+	Changes []schemaChange
+}
+
+// diffSchemas from을 to로 바꾸려면 무엇이 달라졌는지 테이블 이름순으로 비교합니다
+func diffSchemas(from, to map[string]*tableSchema) []tableDiff {
+	names := make(map[string]bool)
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var diffs []tableDiff
+	for _, name := range sorted {
+		a, b := from[name], to[name]
+		switch {
+		case a == nil:
+			diffs = append(diffs, tableDiff{Name: name, Op: "+", To: b})
+		case b == nil:
+			diffs = append(diffs, tableDiff{Name: name, Op: "-", From: a})
+		default:
+			var changes []schemaChange
+			changes = append(changes, diffItems("컬럼", a.Columns, b.Columns)...)
+			changes = append(changes, diffItems("인덱스", a.Indexes, b.Indexes)...)
+			changes = append(changes, diffItems("외래 키", a.ForeignKeys, b.ForeignKeys)...)
+			changes = append(changes, diffItems("CHECK", a.Checks, b.Checks)...)
+			if a.Options != b.Options {
+				changes = append(changes, schemaChange{Op: "~", What: "테이블 옵션", Old: a.Options, New: b.Options})
+			}
+			if len(changes) > 0 {
+				diffs = append(diffs, tableDiff{Name: name, Op: "~", From: a, To: b, Changes: changes})
+			}
+		}
+	}
+	return diffs
+}
+
+// diffItems 이름으로 짝을 지어 추가(to 순서), 변경, 삭제(from 순서)된 항목을 찾습니다
+func diffItems(what string, from, to []schemaItem) []schemaChange {
+	old := make(map[string]string, len(from))
+	for _, item := range from {
+		old[item.Name] = item.Definition
+	}
+	current := make(map[string]bool, len(to))
+
+	var changes []schemaChange
+	for i, item := range to {
+		current[item.Name] = true
+		definition, ok := old[item.Name]
+		switch {
+		case !ok:
+			change := schemaChange{Op: "+", What: what, Name: item.Name, New: item.Definition}
+			if i > 0 {
+				change.After = to[i-1].Name
+			}
+			changes = append(changes, change)
+		case definition != item.Definition:
+			changes = append(changes, schemaChange{Op: "~", What: what, Name: item.Name, Old: definition, New: item.Definition})
+		}
+	}
+	for _, item := range from {
+		if !current[item.Name] {
+			changes = append(changes, schemaChange{Op: "-", What: what, Name: item.Name, Old: item.Definition})
+		}
+	}
+	return changes
+}
+
+// writeSchemaReport 비교 결과를 사람이 읽을 수 있게 기록합니다 (prefix는 각 줄 앞에 붙일 문자열, SQL 주석용)
+func writeSchemaReport(w io.Writer, from, to *schemaSource, diffs []tableDiff, prefix string) {
+	fmt.Fprintf(w, "%s스키마 비교: %s → %s\n", prefix, from.Label, to.Label)
+	if len(diffs) == 0 {
+		fmt.Fprintf(w, "%s차이 없음 (테이블 %d개)\n", prefix, len(from.Tables))
+		return
+	}
+
+	counts := map[string]int{}
+	for _, d := range diffs {
+		counts[d.Op]++
+		fmt.Fprintf(w, "%s%s 테이블 `%s`\n", prefix, d.Op, d.Name)
+		for _, c := range d.Changes {
+			switch c.Op {
+			case "+":
+				fmt.Fprintf(w, "%s    + %s %s\n", prefix, c.What, c.New)
+			case "-":
+				fmt.Fprintf(w, "%s    - %s %s\n", prefix, c.What, c.Old)
+			default:
+				if c.Name != "" {
+					fmt.Fprintf(w, "%s    ~ %s `%s`\n", prefix, c.What, c.Name)
+				} else {
+					fmt.Fprintf(w, "%s    ~ %s\n", prefix, c.What)
+				}
+				fmt.Fprintf(w, "%s        이전: %s\n", prefix, c.Old)
+				fmt.Fprintf(w, "%s        이후: %s\n", prefix, c.New)
+			}
+		}
+	}
+	fmt.Fprintf(w, "%s요약: 테이블 추가 %d, 삭제 %d, 변경 %d\n", prefix, counts["+"], counts["-"], counts["~"])
+}
+
+// alterSQL from을 to로 바꾸는 SQL 스크립트
+// 외래 키를 먼저 지우고, 테이블 삭제/생성과 컬럼/인덱스 변경을 한 뒤 마지막에 외래 키를 추가하므로 참조하는 인덱스와 테이블이 항상 있습니다
+// 이름이 바뀐 테이블이나 컬럼은 삭제 후 추가로 나타나므로 실행하기 전에 확인해야 합니다 (데이터 손실)
+func alterSQL(diffs []tableDiff) string {
+	var b strings.Builder
+	b.WriteString("SET @GOBACK_OLD_FK_CHECKS = @@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS = 0;\n\n")
+
+	// 1. 바뀌거나 없어진 외래 키 삭제
+	for _, d := range diffs {
+		var clauses []string
+		for _, c := range d.Changes {
+			if c.What == "외래 키" && c.Op != "+" {
+				clauses = append(clauses, fmt.Sprintf("DROP FOREIGN KEY `%s`", c.Name))
+			}
+		}
+		writeAlterTable(&b, d.Name, clauses)
+	}
+
+	// 2. 테이블 삭제와 생성
+	var addForeignKeys []string
+	for _, d := range diffs {
+		switch d.Op {
+		case "-":
+			b.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS `%s`;\n", d.Name))
+		case "+":
+			b.WriteString(d.To.Create + ";\n")
+			// 순환 참조 때문에 백업의 CREATE TABLE에서 빠진 외래 키
+			for _, fk := range d.To.ForeignKeys {
+				if !strings.Contains(normalizeForeignKey(d.To.Create), fk.Definition) {
+					addForeignKeys = append(addForeignKeys, fmt.Sprintf("ALTER TABLE `%s` ADD %s;\n", d.Name, fk.Definition))
+				}
+			}
+		}
+	}
+
+	// 3. 인덱스/컬럼/CHECK/옵션 변경
+	for _, d := range diffs {
+		var drops, columns, adds []string
+		for _, c := range d.Changes {
+			switch c.What {
+			case "컬럼":
+				switch c.Op {
+				case "+":
+					position := " FIRST"
+					if c.After != "" {
+						position = fmt.Sprintf(" AFTER `%s`", c.After)
+					}
+					columns = append(columns, "ADD COLUMN "+c.New+position)
+				case "~":
+					columns = append(columns, "MODIFY COLUMN "+c.New)
+				case "-":
+					drops = append(drops, fmt.Sprintf("DROP COLUMN `%s`", c.Name))
+				}
+			case "인덱스":
+				if c.Op != "+" {
+					if c.Name == "PRIMARY" {
+						drops = append(drops, "DROP PRIMARY KEY")
+					} else {
+						drops = append(drops, fmt.Sprintf("DROP INDEX `%s`", c.Name))
+					}
+				}
+				if c.Op != "-" {
+					adds = append(adds, "ADD "+c.New)
+				}
+			case "CHECK":
+				if c.Op != "+" {
+					drops = append(drops, fmt.Sprintf("DROP CONSTRAINT `%s`", c.Name))
+				}
+				if c.Op != "-" {
+					adds = append(adds, "ADD "+c.New)
+				}
+			case "외래 키":
+				if c.Op != "-" {
+					addForeignKeys = append(addForeignKeys, fmt.Sprintf("ALTER TABLE `%s` ADD %s;\n", d.Name, c.New))
+				}
+			case "테이블 옵션":
+				if strings.Contains(c.Old, "PARTITION") || strings.Contains(c.New, "PARTITION") {
+					b.WriteString(fmt.Sprintf("-- 테이블 `%s` 파티션 정의가 다릅니다. 직접 확인하세요: %s\n", d.Name, c.New))
+				} else {
+					adds = append(adds, c.New)
+				}
+			}
+		}
+		writeAlterTable(&b, d.Name, append(append(drops, columns...), adds...))
+	}
+
+	// 4. 외래 키 추가
+	for _, statement := range addForeignKeys {
+		b.WriteString(statement)
+	}
+
+	b.WriteString("\nSET FOREIGN_KEY_CHECKS = @GOBACK_OLD_FK_CHECKS;\n")
+	return b.String()
+}
+
+func writeAlterTable(b *strings.Builder, tableName string, clauses []string) {
+	if len(clauses) == 0 {
+		return
+	}
+	b.WriteString(fmt.Sprintf("ALTER TABLE `%s`\n  %s;\n", tableName, strings.Join(clauses, ",\n  ")))
+}
+
+// runDiff diff 하위 명령: 두 백업 또는 백업과 라이브 데이터베이스의 스키마를 비교합니다
+// 종료 코드는 차이가 없으면 0, 있으면 exitCodeDifferent, 오류면 exitCodeFailure입니다
+func runDiff(ctx context.Context, config *BackupConfig, args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	alter := flags.Bool("alter", false, "보고서 대신 <기준>을 <대상>으로 바꾸는 ALTER 스크립트 출력 (보고서는 주석으로 포함)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "사용법: %s diff [-alter] <기준> <대상>\n", filepath.Base(os.Args[0]))
+		fmt.Fprintln(flags.Output(), "  <기준>, <대상>: goback 백업 파일(.sql)이나 디렉토리, 또는 live / live:<데이터베이스> (라이브 데이터베이스)")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitCodeFailure
+	}
+
+	from, err := loadSchemaSource(ctx, config, flags.Arg(0))
+	if err != nil {
+		slog.Error("기준 스키마를 읽을 수 없습니다", "source", flags.Arg(0), "error", err)
+		return exitCode(err)
+	}
+	to, err := loadSchemaSource(ctx, config, flags.Arg(1))
+	if err != nil {
+		slog.Error("대상 스키마를 읽을 수 없습니다", "source", flags.Arg(1), "error", err)
+		return exitCode(err)
+	}
+
+	diffs := diffSchemas(from.Tables, to.Tables)
+	if *alter {
+		writeSchemaReport(os.Stdout, from, to, diffs, "-- ")
+		fmt.Fprintln(os.Stdout)
+		fmt.Fprint(os.Stdout, alterSQL(diffs))
+	} else {
+		writeSchemaReport(os.Stdout, from, to, diffs, "")
+	}
+	if len(diffs) > 0 {
+		return exitCodeDifferent
+	}
+	return 0
+}
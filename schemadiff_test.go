@@ -0,0 +1,167 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const usersCreateV1 = "CREATE TABLE `users` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `email` varchar(255) NOT NULL,\n" +
+	"  `team_id` int DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `uk_email` (`email`),\n" +
+	"  KEY `idx_team` (`team_id`),\n" +
+	"  CONSTRAINT `fk_team` FOREIGN KEY (`team_id`) REFERENCES `teams` (`id`) ON DELETE RESTRICT,\n" +
+	"  CONSTRAINT `chk_email` CHECK ((`email` <> _utf8mb4''))\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4;"
+
+const usersCreateV2 = "CREATE TABLE `users` (\n" +
+	"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+	"  `name` varchar(100) DEFAULT NULL,\n" +
+	"  `email` varchar(320) NOT NULL,\n" +
+	"  `team_id` int DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  KEY `idx_team` (`team_id`),\n" +
+	"  KEY `idx_name` (`name`),\n" +
+	"  CONSTRAINT `fk_team` FOREIGN KEY (`team_id`) REFERENCES `teams` (`id`) ON DELETE CASCADE\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8mb4"
+
+func mustParseCreateTable(t *testing.T, statement string) *tableSchema {
+	t.Helper()
+	table, err := parseCreateTable(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestParseCreateTable(t *testing.T) {
+	table := mustParseCreateTable(t, usersCreateV1)
+	if table.Name != "users" {
+		t.Errorf("Name = %q", table.Name)
+	}
+	names := func(items []schemaItem) string {
+		var s []string
+		for _, item := range items {
+			s = append(s, item.Name)
+		}
+		return strings.Join(s, ",")
+	}
+	if got := names(table.Columns); got != "id,email,team_id" {
+		t.Errorf("columns = %s", got)
+	}
+	if got := names(table.Indexes); got != "PRIMARY,uk_email,idx_team" {
+		t.Errorf("indexes = %s", got)
+	}
+	if got := names(table.Checks); got != "chk_email" {
+		t.Errorf("checks = %s", got)
+	}
+	if len(table.ForeignKeys) != 1 || strings.Contains(table.ForeignKeys[0].Definition, "RESTRICT") {
+		t.Errorf("foreign keys = %+v, want RESTRICT normalized away", table.ForeignKeys)
+	}
+	if table.Columns[0].Definition != "`id` bigint NOT NULL AUTO_INCREMENT" {
+		t.Errorf("column definition = %q, want trailing comma removed", table.Columns[0].Definition)
+	}
+	// AUTO_INCREMENT 카운터 값은 비교에서 제외
+	if table.Options != "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4" {
+		t.Errorf("Options = %q", table.Options)
+	}
+
+	partitioned := mustParseCreateTable(t, "CREATE TABLE `we``ird` (\n"+
+		"  `id` int NOT NULL\n"+
+		") ENGINE=InnoDB\n"+
+		"/*!50100 PARTITION BY HASH (`id`)\n"+
+		"PARTITIONS 4 */")
+	if partitioned.Name != "we`ird" || !strings.HasSuffix(partitioned.Options, "PARTITIONS 4 */") {
+		t.Errorf("partitioned = %q, options %q", partitioned.Name, partitioned.Options)
+	}
+
+	if _, err := parseCreateTable("CREATE VIEW `v` AS SELECT 1"); err == nil {
+		t.Error("parseCreateTable(CREATE VIEW) succeeded, want error")
+	}
+}
+
+func TestNormalizeForeignKeyMatchesAddConstraint(t *testing.T) {
+	// SHOW CREATE TABLE의 정의와 백업 끝에 지연된 ALTER TABLE의 정의가 같게 비교되어야 함
+	shown := "CONSTRAINT `fk_order` FOREIGN KEY (`tenant_id`, `order_id`) REFERENCES `orders` (`tenant_id`, `id`)"
+	f := ForeignKey{Name: "fk_order", Table: "items", Columns: []string{"tenant_id", "order_id"},
+		RefTable: "orders", RefColumns: []string{"tenant_id", "id"}, OnUpdate: "NO ACTION", OnDelete: "RESTRICT"}
+	m := addConstraintPattern.FindStringSubmatch(f.AddConstraintSQL())
+	if m == nil {
+		t.Fatalf("addConstraintPattern did not match %s", f.AddConstraintSQL())
+	}
+	if normalizeForeignKey(m[2]) != normalizeForeignKey(shown) {
+		t.Errorf("normalized definitions differ:\n%s\n%s", normalizeForeignKey(m[2]), normalizeForeignKey(shown))
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	teams := mustParseCreateTable(t, "CREATE TABLE `teams` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB")
+	logs := mustParseCreateTable(t, "CREATE TABLE `logs` (\n  `id` int NOT NULL\n) ENGINE=InnoDB")
+	from := map[string]*tableSchema{"users": mustParseCreateTable(t, usersCreateV1), "teams": teams, "logs": logs}
+	to := map[string]*tableSchema{"users": mustParseCreateTable(t, usersCreateV2), "teams": teams,
+		"audit": mustParseCreateTable(t, "CREATE TABLE `audit` (\n  `id` int NOT NULL\n) ENGINE=InnoDB")}
+
+	diffs := diffSchemas(from, to)
+	if len(diffs) != 3 || diffs[0].Name != "audit" || diffs[0].Op != "+" || diffs[1].Name != "logs" || diffs[1].Op != "-" ||
+		diffs[2].Name != "users" || diffs[2].Op != "~" {
+		t.Fatalf("diffs = %+v", diffs)
+	}
+
+	var got []string
+	for _, c := range diffs[2].Changes {
+		got = append(got, c.Op+c.What+":"+c.Name+"@"+c.After)
+	}
+	want := []string{
+		"+컬럼:name@id",
+		"~컬럼:email@",
+		"+인덱스:idx_name@idx_team",
+		"-인덱스:uk_email@",
+		"~외래 키:fk_team@",
+		"-CHECK:chk_email@",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if diffs := diffSchemas(from, from); len(diffs) != 0 {
+		t.Errorf("identical schemas gave %+v", diffs)
+	}
+}
+
+func TestAlterSQL(t *testing.T) {
+	from := map[string]*tableSchema{"users": mustParseCreateTable(t, usersCreateV1)}
+	to := map[string]*tableSchema{"users": mustParseCreateTable(t, usersCreateV2)}
+	got := alterSQL(diffSchemas(from, to))
+
+	want := "SET @GOBACK_OLD_FK_CHECKS = @@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS = 0;\n\n" +
+		"ALTER TABLE `users`\n" +
+		"  DROP FOREIGN KEY `fk_team`;\n" +
+		"ALTER TABLE `users`\n" +
+		"  DROP INDEX `uk_email`,\n" +
+		"  DROP CONSTRAINT `chk_email`,\n" +
+		"  ADD COLUMN `name` varchar(100) DEFAULT NULL AFTER `id`,\n" +
+		"  MODIFY COLUMN `email` varchar(320) NOT NULL,\n" +
+		"  ADD KEY `idx_name` (`name`);\n" +
+		"ALTER TABLE `users` ADD CONSTRAINT `fk_team` FOREIGN KEY (`team_id`) REFERENCES `teams` (`id`) ON DELETE CASCADE;\n" +
+		"\nSET FOREIGN_KEY_CHECKS = @GOBACK_OLD_FK_CHECKS;\n"
+	if got != want {
+		t.Errorf("alterSQL =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAlterSQLNewTableWithDeferredForeignKey(t *testing.T) {
+	// 순환 참조로 CREATE TABLE에서 빠지고 백업 끝에 ALTER TABLE로 추가된 외래 키
+	created := mustParseCreateTable(t, "CREATE TABLE `a` (\n  `id` int NOT NULL,\n  `b_id` int DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB")
+	created.ForeignKeys = append(created.ForeignKeys, schemaItem{Name: "fk_b",
+		Definition: "CONSTRAINT `fk_b` FOREIGN KEY (`b_id`) REFERENCES `b` (`id`)"})
+	got := alterSQL(diffSchemas(map[string]*tableSchema{"old": created}, map[string]*tableSchema{"a": created}))
+
+	createAt := strings.Index(got, "CREATE TABLE `a`")
+	dropAt := strings.Index(got, "DROP TABLE IF EXISTS `old`;")
+	fkAt := strings.Index(got, "ALTER TABLE `a` ADD CONSTRAINT `fk_b` FOREIGN KEY (`b_id`) REFERENCES `b` (`id`);")
+	if createAt < 0 || dropAt < 0 || fkAt < createAt {
+		t.Errorf("alterSQL =\n%s", got)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// streamMerger 워커 결과를 원래 순서대로 기다렸다가 차례가 된 테이블을 바로 w에 이어 씁니다 (-o -)
+// 차례가 오기 전에 끝난 테이블만 파트 파일에 남고, 기록한 파트 파일은 즉시 삭제합니다
+// 파티션으로 나눈 테이블은 모든 파티션이 끝난 뒤에 함께 기록합니다 (한 파티션이 실패하면 테이블 전체를 제외)
+type streamMerger struct {
+	w       *bufio.Writer
+	units   []backupUnit
+	results []*TableBackupResult
+	next    int // 다음에 기록할 단위 순서
+	logger  *slog.Logger
+}
+
+func newStreamMerger(w io.Writer, units []backupUnit, logger *slog.Logger) *streamMerger {
+	return &streamMerger{
+		w:       bufio.NewWriterSize(w, 1024*1024),
+		units:   units,
+		results: make([]*TableBackupResult, len(units)),
+		logger:  logger,
+	}
+}
+
+// WriteHeader 워커가 시작되기 전에 헤더를 먼저 내보냅니다
+func (m *streamMerger) WriteHeader(header string) error {
+	if _, err := m.w.WriteString(header); err != nil {
+		return fmt.Errorf("헤더 작성 실패: %v", err)
+	}
+	return m.w.Flush()
+}
+
+// Add 결과를 받아 순서가 이어지는 테이블까지 모두 기록합니다
+func (m *streamMerger) Add(result TableBackupResult) error {
+	m.results[result.Index] = &result
+
+	for m.next < len(m.results) {
+		// 같은 테이블의 단위(파티션)가 모두 끝나야 기록
+		end := m.next
+		var failed *TableBackupResult
+		for end < len(m.units) && m.units[end].Table == m.units[m.next].Table {
+			r := m.results[end]
+			if r == nil {
+				return m.w.Flush()
+			}
+			if r.Error != nil && failed == nil {
+				failed = r
+			}
+			end++
+		}
+		group := m.results[m.next:end]
+		m.next = end
+
+		if failed != nil {
+			m.logger.Warn("실패한 테이블을 출력에서 제외합니다", "table", unitName(failed.TableName, failed.Partition), "error", failed.Error)
+			continue
+		}
+		for _, r := range group {
+			if err := appendPartFile(m.w, r.TempFile); err != nil {
+				return fmt.Errorf("출력 쓰기 실패: %v", err)
+			}
+			if err := os.Remove(r.TempFile); err != nil {
+				m.logger.Warn("파트 파일 삭제 실패", "file", r.TempFile, "error", err)
+			}
+		}
+	}
+
+	// 다음 테이블을 기다리는 동안 받는 쪽이 처리할 수 있도록 바로 내보냄
+	return m.w.Flush()
+}
+
+// Close 모든 테이블을 기록한 뒤 푸터(완료 여부 표시 포함)를 기록합니다
+func (m *streamMerger) Close(footer string) error {
+	if m.next < len(m.results) {
+		return fmt.Errorf("기록되지 않은 테이블이 남아있습니다 (%d/%d)", m.next, len(m.results))
+	}
+	if _, err := m.w.WriteString(footer); err != nil {
+		return fmt.Errorf("푸터 작성 실패: %v", err)
+	}
+	if err := m.w.Flush(); err != nil {
+		return fmt.Errorf("출력 쓰기 실패: %v", err)
+	}
+	return nil
+}
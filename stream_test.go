@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// streamTestResults 단위마다 "<단위 이름>\n" 내용의 파트 파일을 만들고 결과를 반환합니다
+func streamTestResults(t *testing.T, units []backupUnit) []TableBackupResult {
+	t.Helper()
+	dir := t.TempDir()
+	results := make([]TableBackupResult, len(units))
+	for i, unit := range units {
+		path := filepath.Join(dir, unit.partFileName(i, ".sql"))
+		if err := os.WriteFile(path, []byte(unit.Name()+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		results[i] = TableBackupResult{TableName: unit.Table, Partition: unit.Partition, Index: i, TempFile: path}
+	}
+	return results
+}
+
+func TestStreamMergerOrder(t *testing.T) {
+	units := []backupUnit{
+		{Table: "users", Structure: true},
+		{Table: "events", Partition: "p0", Structure: true},
+		{Table: "events", Partition: "p1"},
+		{Table: "orders", Structure: true},
+	}
+	results := streamTestResults(t, units)
+
+	var out bytes.Buffer
+	m := newStreamMerger(&out, units, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := m.WriteHeader("-- header\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 끝나는 순서와 관계없이 단위 순서대로, 파티션 테이블은 모든 파티션이 끝난 뒤에 기록
+	steps := []struct {
+		index int
+		want  string
+	}{
+		{3, "-- header\n"},
+		{2, "-- header\n"},
+		{0, "-- header\nusers\n"},
+		{1, "-- header\nusers\nevents 파티션 p0\nevents 파티션 p1\norders\n"},
+	}
+	for _, step := range steps {
+		if err := m.Add(results[step.index]); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != step.want {
+			t.Fatalf("after unit %d:\n%q\nwant\n%q", step.index, out.String(), step.want)
+		}
+	}
+
+	if err := m.Close("-- footer\n"); err != nil {
+		t.Fatal(err)
+	}
+	if want := steps[len(steps)-1].want + "-- footer\n"; out.String() != want {
+		t.Errorf("after Close:\n%q\nwant\n%q", out.String(), want)
+	}
+	for _, r := range results {
+		if _, err := os.Stat(r.TempFile); !os.IsNotExist(err) {
+			t.Errorf("part file %s not removed", r.TempFile)
+		}
+	}
+}
+
+func TestStreamMergerSkipsFailedTable(t *testing.T) {
+	units := []backupUnit{
+		{Table: "events", Partition: "p0", Structure: true},
+		{Table: "events", Partition: "p1"},
+		{Table: "orders", Structure: true},
+	}
+	results := streamTestResults(t, units)
+	results[1].Error = errors.New("boom")
+
+	var out bytes.Buffer
+	m := newStreamMerger(&out, units, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for _, i := range []int{2, 1, 0} {
+		if err := m.Add(results[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 파티션 하나가 실패하면 성공한 파티션도 기록하지 않음
+	if err := m.Close("-- footer\n"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "orders\n-- footer\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestStreamMergerCloseBeforeAllUnits(t *testing.T) {
+	units := []backupUnit{{Table: "users", Structure: true}, {Table: "orders", Structure: true}}
+	results := streamTestResults(t, units)
+
+	var out bytes.Buffer
+	m := newStreamMerger(&out, units, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := m.Add(results[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close("-- footer\n"); err == nil {
+		t.Error("Close succeeded with units still pending")
+	}
+	if out.Len() != 0 {
+		t.Errorf("footer written before all units: %q", out.String())
+	}
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// subsetChunkSize IN 목록 하나에 넣는 키 수 (플레이스홀더 제한과 쿼리 길이 고려)
+const subsetChunkSize = 1000
+
+// SubsetRoot 표본을 뽑을 루트 테이블과 조건
+// where, percent, limit은 함께 쓸 수 있으며 where → percent → limit 순서로 적용됩니다
+type SubsetRoot struct {
+	Table   string  `json:"table"`
+	Where   string  `json:"where,omitempty"`   // SQL 조건식 (예: created_at >= '2025-01-01')
+	Percent float64 `json:"percent,omitempty"` // 무작위 표본 비율 (0 < percent <= 100)
+	Limit   int     `json:"limit,omitempty"`   // 기본 키 순서로 최대 행 수
+}
+
+// SubsetSpec 부분 추출 설정 파일 형식
+type SubsetSpec struct {
+	Roots           []SubsetRoot `json:"roots"`
+	IncludeChildren bool         `json:"include_children"` // 루트 행을 참조하는 자식 행도 포함
+	Seed            *int64       `json:"seed,omitempty"`   // percent 표본의 RAND 시드 (지정하면 같은 데이터에서 같은 표본)
+}
+
+// LoadSubsetSpec 부분 추출 설정 파일을 읽습니다
+func LoadSubsetSpec(path string) (*SubsetSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("부분 추출 설정 파일 읽기 실패: %v", err)
+	}
+
+	var spec SubsetSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("부분 추출 설정 파일 해석 실패: %v", err)
+	}
+	if len(spec.Roots) == 0 {
+		return nil, fmt.Errorf("부분 추출 루트 테이블이 없습니다")
+	}
+	for _, root := range spec.Roots {
+		if root.Table == "" {
+			return nil, fmt.Errorf("부분 추출 루트에 table이 없습니다")
+		}
+		if root.Percent < 0 || root.Percent > 100 {
+			return nil, fmt.Errorf("루트 '%s'의 percent는 0~100 사이여야 합니다", root.Table)
+		}
+		if root.Limit < 0 {
+			return nil, fmt.Errorf("루트 '%s'의 limit은 0 이상이어야 합니다", root.Table)
+		}
+	}
+	return &spec, nil
+}
+
+// RootNames 로그와 헤더에 표시할 루트 테이블 목록
+func (s *SubsetSpec) RootNames() string {
+	names := make([]string, len(s.Roots))
+	for i, root := range s.Roots {
+		names[i] = root.Table
+	}
+	return strings.Join(names, ", ")
+}
+
+// keySet 테이블에서 선택된 행들의 기본 키 (추가된 순서 유지, 중복 제거)
+type keySet struct {
+	columns []string
+	keys    [][]interface{}
+	seen    map[string]struct{}
+}
+
+func newKeySet(columns []string) *keySet {
+	return &keySet{columns: columns, seen: make(map[string]struct{})}
+}
+
+// add 새 키면 추가하고 true를 반환합니다
+func (k *keySet) add(key []interface{}) bool {
+	id := keyString(key)
+	if _, ok := k.seen[id]; ok {
+		return false
+	}
+	k.seen[id] = struct{}{}
+	k.keys = append(k.keys, key)
+	return true
+}
+
+// keyString 중복 확인용 키 문자열 (드라이버가 같은 값을 []byte나 정수로 줄 수 있으므로 문자열로 통일)
+// 복합 키의 값에 구분 문자가 들어 있어도 다른 키와 겹치지 않도록 값마다 길이를 붙이고, NULL은 따로 표시합니다
+func keyString(key []interface{}) string {
+	var b strings.Builder
+	for _, v := range key {
+		var s string
+		switch v := v.(type) {
+		case nil:
+			b.WriteString("-;")
+			continue
+		case []byte:
+			s = string(v)
+		default:
+			s = fmt.Sprint(v)
+		}
+		fmt.Fprintf(&b, "%d:%s;", len(s), s)
+	}
+	return b.String()
+}
+
+// Subset 외래 키를 따라 참조 무결성이 유지되도록 고른 테이블별 행 집합
+// 선택된 행이 참조하는 부모 행은 항상 포함되므로 FOREIGN_KEY_CHECKS를 끄지 않아도 복원됩니다
+type Subset struct {
+	tables map[string]*keySet
+}
+
+// RowCount 테이블에서 선택된 행 수
+func (s *Subset) RowCount(tableName string) int64 {
+	if ks := s.tables[tableName]; ks != nil {
+		return int64(len(ks.keys))
+	}
+	return 0
+}
+
+// RowCounts 진행률 추정에 쓸 테이블별 선택 행 수
+func (s *Subset) RowCounts() map[string]int64 {
+	counts := make(map[string]int64, len(s.tables))
+	for table, ks := range s.tables {
+		counts[table] = int64(len(ks.keys))
+	}
+	return counts
+}
+
+// loadPrimaryKeys 테이블별 기본 키 컬럼 (순서대로)
+func (mb *MySQLBackup) loadPrimaryKeys(ctx context.Context) (map[string][]string, error) {
+	query := `
+		SELECT TABLE_NAME, COLUMN_NAME
+		FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? AND CONSTRAINT_NAME = 'PRIMARY'
+		ORDER BY TABLE_NAME, ORDINAL_POSITION`
+
+	rows, err := mb.db.QueryContext(ctx, query, mb.config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("기본 키 조회 실패: %v", err)
+	}
+	defer rows.Close()
+
+	pks := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("기본 키 스캔 실패: %v", err)
+		}
+		pks[table] = append(pks[table], column)
+	}
+	return pks, rows.Err()
+}
+
+// buildSubset 루트 표본을 뽑고 외래 키를 따라 필요한 행을 모읍니다
+// include_children이면 먼저 루트에서 자식 방향으로 내려간 뒤, 선택된 모든 행의 부모를 끝까지 따라 올라갑니다
+// 부모를 따라 올라가며 추가된 행의 자식은 다시 포함하지 않으므로 표본이 데이터베이스 전체로 번지지 않습니다
+func (mb *MySQLBackup) buildSubset(ctx context.Context, spec *SubsetSpec, tables []string, fks []ForeignKey) (*Subset, error) {
+	pks, err := mb.loadPrimaryKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		known[table] = true
+	}
+
+	subset := &Subset{tables: make(map[string]*keySet)}
+	keySetFor := func(table string) (*keySet, error) {
+		if ks := subset.tables[table]; ks != nil {
+			return ks, nil
+		}
+		if len(pks[table]) == 0 {
+			return nil, fmt.Errorf("테이블 '%s'에 기본 키가 없어 부분 추출할 행을 식별할 수 없습니다", table)
+		}
+		ks := newKeySet(pks[table])
+		subset.tables[table] = ks
+		return ks, nil
+	}
+
+	// 루트 표본
+	for _, root := range spec.Roots {
+		if !known[root.Table] {
+			return nil, fmt.Errorf("부분 추출 루트 테이블 '%s'이(가) 없습니다", root.Table)
+		}
+		ks, err := keySetFor(root.Table)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := mb.queryKeys(ctx, subsetRootQuery(root, ks.columns, spec.Seed))
+		if err != nil {
+			return nil, fmt.Errorf("루트 테이블 '%s' 표본 조회 실패: %v", root.Table, err)
+		}
+		for _, key := range keys {
+			ks.add(key)
+		}
+		mb.logger.Info("부분 추출 루트 표본", "table", root.Table, "rows", len(keys))
+	}
+
+	// 현재 데이터베이스의 테이블 사이 외래 키만 따라감
+	var edges []ForeignKey
+	for _, fk := range fks {
+		if known[fk.Table] && known[fk.RefTable] {
+			edges = append(edges, fk)
+		}
+	}
+
+	if spec.IncludeChildren {
+		if err := mb.expandSubset(ctx, subset, edges, false, keySetFor); err != nil {
+			return nil, err
+		}
+	}
+	if err := mb.expandSubset(ctx, subset, edges, true, keySetFor); err != nil {
+		return nil, err
+	}
+
+	return subset, nil
+}
+
+// subsetRootQuery 루트 테이블의 표본 기본 키를 고르는 쿼리
+func subsetRootQuery(root SubsetRoot, pkColumns []string, seed *int64) string {
+	query := fmt.Sprintf("SELECT %s FROM `%s`", quoteColumns(pkColumns), root.Table)
+
+	var conditions []string
+	if root.Where != "" {
+		conditions = append(conditions, "("+root.Where+")")
+	}
+	if root.Percent > 0 && root.Percent < 100 {
+		rand := "RAND()"
+		if seed != nil {
+			rand = fmt.Sprintf("RAND(%d)", *seed)
+		}
+		conditions = append(conditions, fmt.Sprintf("%s < %s", rand, strconv.FormatFloat(root.Percent/100, 'f', -1, 64)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if root.Limit > 0 {
+		query += fmt.Sprintf(" ORDER BY %s LIMIT %d", quoteColumns(pkColumns), root.Limit)
+	}
+	return query
+}
+
+// expandSubset 외래 키를 한 방향으로 따라가며 새로 추가되는 행이 없을 때까지 반복합니다
+// upward면 자식 → 부모(참조되는 행), 아니면 부모 → 자식(참조하는 행) 방향입니다
+// 외래 키마다 처리한 키 위치를 기억해 새로 추가된 행만 다시 조회합니다
+func (mb *MySQLBackup) expandSubset(ctx context.Context, subset *Subset, fks []ForeignKey, upward bool,
+	keySetFor func(string) (*keySet, error)) error {
+	processed := make([]int, len(fks))
+
+	for {
+		changed := false
+		for i, fk := range fks {
+			from, fromColumns, to, toColumns := fk.RefTable, fk.RefColumns, fk.Table, fk.Columns
+			if upward {
+				from, fromColumns, to, toColumns = fk.Table, fk.Columns, fk.RefTable, fk.RefColumns
+			}
+
+			fromSet := subset.tables[from]
+			if fromSet == nil || processed[i] == len(fromSet.keys) {
+				continue
+			}
+			newKeys := fromSet.keys[processed[i]:]
+			processed[i] = len(fromSet.keys)
+
+			toSet, err := keySetFor(to)
+			if err != nil {
+				return err
+			}
+			added, err := mb.followForeignKey(ctx, from, fromSet, fromColumns, newKeys, to, toSet, toColumns)
+			if err != nil {
+				return fmt.Errorf("외래 키 %s.%s 추적 실패: %v", fk.Table, fk.Name, err)
+			}
+			if added > 0 {
+				changed = true
+				mb.logger.Debug("외래 키를 따라 행을 추가했습니다",
+					"constraint", fk.Name, "from", from, "to", to, "rows", added)
+			}
+		}
+		if !changed {
+			return nil
+		}
+	}
+}
+
+// followForeignKey from 테이블의 선택된 행에서 외래 키 컬럼 값을 읽고 to 테이블에서 그 값과 연결된 행을 추가합니다
+func (mb *MySQLBackup) followForeignKey(ctx context.Context, from string, fromSet *keySet, fromColumns []string, keys [][]interface{},
+	to string, toSet *keySet, toColumns []string) (int, error) {
+	// 연결 컬럼이 기본 키 자체면 키를 그대로 사용
+	values := keys
+	if !sameColumns(fromColumns, fromSet.columns) {
+		values = nil
+		for start := 0; start < len(keys); start += subsetChunkSize {
+			chunk := keys[start:min(start+subsetChunkSize, len(keys))]
+			where, args := tupleInClause(fromSet.columns, chunk)
+			query := fmt.Sprintf("SELECT DISTINCT %s FROM `%s` WHERE %s", quoteColumns(fromColumns), from, where)
+			rows, err := mb.queryKeys(ctx, query, args...)
+			if err != nil {
+				return 0, err
+			}
+			values = append(values, rows...)
+		}
+	}
+
+	// NULL이 포함된 외래 키 값은 아무 행도 참조하지 않음
+	lookup := make([][]interface{}, 0, len(values))
+	for _, value := range values {
+		if !containsNil(value) {
+			lookup = append(lookup, value)
+		}
+	}
+
+	added := 0
+	for start := 0; start < len(lookup); start += subsetChunkSize {
+		chunk := lookup[start:min(start+subsetChunkSize, len(lookup))]
+		where, args := tupleInClause(toColumns, chunk)
+		query := fmt.Sprintf("SELECT %s FROM `%s` WHERE %s", quoteColumns(toSet.columns), to, where)
+		rows, err := mb.queryKeys(ctx, query, args...)
+		if err != nil {
+			return added, err
+		}
+		for _, key := range rows {
+			if toSet.add(key) {
+				added++
+			}
+		}
+	}
+	return added, nil
+}
+
+// queryKeys 쿼리 결과를 행 단위 값 목록으로 읽습니다
+// 정수 컬럼은 텍스트 프로토콜의 []byte를 정수로 바꿔 이후 IN 조건에서 정확히 비교되도록 합니다
+func (mb *MySQLBackup) queryKeys(ctx context.Context, query string, args ...interface{}) ([][]interface{}, error) {
+	rows, err := mb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columnTypes))
+		valuePtrs := make([]interface{}, len(columnTypes))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			values[i] = normalizeKeyValue(value, columnTypes[i].DatabaseTypeName())
+		}
+		result = append(result, values)
+	}
+	return result, rows.Err()
+}
+
+func normalizeKeyValue(value interface{}, typeName string) interface{} {
+	b, ok := value.([]byte)
+	if !ok || !strings.Contains(typeName, "INT") {
+		return value
+	}
+	if strings.HasPrefix(typeName, "UNSIGNED") {
+		if n, err := strconv.ParseUint(string(b), 10, 64); err == nil {
+			return n
+		}
+	} else if n, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+		return n
+	}
+	return value
+}
+
+// getTableDataSubset 부분 추출로 선택된 행만 기본 키 순서 묶음으로 읽어 기록합니다
+func (mb *MySQLBackup) getTableDataSubset(ctx context.Context, tableName string, sink *tableSink) (int64, error) {
+	ks := mb.subset.tables[tableName]
+	if ks == nil {
+		return 0, nil
+	}
+
+	var rowCount int64
+	for start := 0; start < len(ks.keys); start += subsetChunkSize {
+		chunk := ks.keys[start:min(start+subsetChunkSize, len(ks.keys))]
+		where, args := tupleInClause(ks.columns, chunk)
+		query := fmt.Sprintf("SELECT %s FROM `%s` WHERE %s ORDER BY %s", sink.selectList(), tableName, where, quoteColumns(ks.columns))
+
+		if err := mb.replica.WaitForLag(ctx, tableName); err != nil {
+			return 0, err
+		}
+
+		var batch *rowBatch
+		err := mb.withRetry(ctx, tableName, start, func() error {
+			var err error
+			batch, err = mb.queryBatch(ctx, tableName, "", query, args...)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		if err := sink.WriteBatch(batch); err != nil {
+			return 0, err
+		}
+		count := int64(len(batch.rows))
+		rowCount += count
+		mb.progress.AddRows(tableName, count)
+	}
+	return rowCount, nil
+}
+
+// tupleInClause 컬럼 목록과 값 목록으로 IN 조건과 인자를 만듭니다 (복합 키는 행 생성자 사용)
+func tupleInClause(columns []string, values [][]interface{}) (string, []interface{}) {
+	placeholder := "?"
+	if len(columns) > 1 {
+		placeholder = "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	}
+
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, 0, len(values)*len(columns))
+	for i, value := range values {
+		placeholders[i] = placeholder
+		args = append(args, value...)
+	}
+
+	target := quoteColumns(columns)
+	if len(columns) > 1 {
+		target = "(" + target + ")"
+	}
+	return fmt.Sprintf("%s IN (%s)", target, strings.Join(placeholders, ", ")), args
+}
+
+func quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = fmt.Sprintf("`%s`", column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsNil(values []interface{}) bool {
+	for _, v := range values {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubsetRootQuery(t *testing.T) {
+	seed := int64(42)
+	tests := []struct {
+		name string
+		root SubsetRoot
+		pk   []string
+		seed *int64
+		want string
+	}{
+		{"all rows", SubsetRoot{Table: "users"}, []string{"id"}, nil,
+			"SELECT `id` FROM `users`"},
+		{"where is parenthesized", SubsetRoot{Table: "users", Where: "a = 1 OR b = 2"}, []string{"id"}, nil,
+			"SELECT `id` FROM `users` WHERE (a = 1 OR b = 2)"},
+		{"percent", SubsetRoot{Table: "users", Percent: 0.5}, []string{"id"}, nil,
+			"SELECT `id` FROM `users` WHERE RAND() < 0.005"},
+		{"seeded percent with where and limit", SubsetRoot{Table: "orders", Where: "status = 'paid'", Percent: 10, Limit: 100},
+			[]string{"tenant_id", "id"}, &seed,
+			"SELECT `tenant_id`, `id` FROM `orders` WHERE (status = 'paid') AND RAND(42) < 0.1 ORDER BY `tenant_id`, `id` LIMIT 100"},
+		{"100 percent selects everything", SubsetRoot{Table: "users", Percent: 100}, []string{"id"}, &seed,
+			"SELECT `id` FROM `users`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subsetRootQuery(tt.root, tt.pk, tt.seed); got != tt.want {
+				t.Errorf("subsetRootQuery =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTupleInClause(t *testing.T) {
+	where, args := tupleInClause([]string{"id"}, [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}})
+	if where != "`id` IN (?, ?, ?)" || !reflect.DeepEqual(args, []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Errorf("single column: %s %v", where, args)
+	}
+
+	where, args = tupleInClause([]string{"tenant_id", "id"}, [][]interface{}{{int64(1), "a"}, {int64(1), "b"}})
+	if where != "(`tenant_id`, `id`) IN ((?, ?), (?, ?))" || !reflect.DeepEqual(args, []interface{}{int64(1), "a", int64(1), "b"}) {
+		t.Errorf("composite key: %s %v", where, args)
+	}
+}
+
+func TestKeySetDedup(t *testing.T) {
+	ks := newKeySet([]string{"a", "b"})
+	keys := [][]interface{}{
+		{int64(1), []byte("x")},
+		{[]byte("1"), "x"}, // 드라이버가 []byte나 정수로 준 같은 값
+		{"a\x00", "b"},
+		{"a", "\x00b"}, // 구분 문자가 들어 있어도 다른 키
+		{"1:a;", "b"},
+		{"1", "a;1:b"},
+		{nil, "x"},
+		{"<nil>", "x"},
+		{nil, "x"},
+	}
+	var added []int
+	for i, key := range keys {
+		if ks.add(key) {
+			added = append(added, i)
+		}
+	}
+	if want := []int{0, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added keys %v, want %v", added, want)
+	}
+	if len(ks.keys) != 7 {
+		t.Errorf("keySet has %d keys", len(ks.keys))
+	}
+}
+
+func TestNormalizeKeyValueAndNulls(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		typeName string
+		want     interface{}
+	}{
+		{[]byte("42"), "INT", int64(42)},
+		{[]byte("-42"), "BIGINT", int64(-42)},
+		{[]byte("18446744073709551615"), "UNSIGNED BIGINT", uint64(18446744073709551615)},
+		{[]byte("abc"), "VARCHAR", []byte("abc")},
+		{nil, "INT", nil},
+	}
+	for _, tt := range tests {
+		if got := normalizeKeyValue(tt.value, tt.typeName); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("normalizeKeyValue(%v, %s) = %#v, want %#v", tt.value, tt.typeName, got, tt.want)
+		}
+	}
+
+	if !containsNil([]interface{}{int64(1), nil}) || containsNil([]interface{}{int64(1), ""}) {
+		t.Error("containsNil")
+	}
+	if !sameColumns([]string{"a", "b"}, []string{"a", "b"}) || sameColumns([]string{"a", "b"}, []string{"b", "a"}) || sameColumns([]string{"a"}, nil) {
+		t.Error("sameColumns")
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// throttleChunkRows 행/바이트 토큰을 한 번에 가져가는 행 수 (행마다 잠그지 않도록 묶어서 처리)
+const throttleChunkRows = 100
+
+// tokenBucket 모든 워커가 공유하는 초당 처리량 제한
+// 가져갈 토큰이 모자라면 미리 빌려 쓰고 부족한 만큼 기다리므로 큰 요청도 순서대로 공정하게 처리됩니다
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // 초당 토큰 수
+	burst  float64 // 최대로 쌓이는 토큰 수 (1초 분량)
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int64) *tokenBucket {
+	return &tokenBucket{
+		rate:   float64(rate),
+		burst:  float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+// Wait n개의 토큰을 가져가고, 빌려 쓴 만큼 기다립니다
+func (b *tokenBucket) Wait(ctx context.Context, n int64) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	return sleepContext(ctx, wait)
+}
+
+// Throttle 운영 중인 프라이머리를 보호하기 위해 모든 워커의 처리량과 동시 쿼리 수를 제한합니다
+// 서버 상태 변수(Threads_running 등)가 임계값을 넘으면 내려갈 때까지 새 쿼리와 행 읽기를 멈춥니다
+// 제한을 하나도 설정하지 않으면 nil이며, nil이면 모든 메서드가 아무것도 하지 않습니다
+type Throttle struct {
+	rows    *tokenBucket  // 초당 행 수 (설정하지 않으면 nil)
+	bytes   *tokenBucket  // 초당 바이트 수 (설정하지 않으면 nil)
+	queries chan struct{} // 동시 데이터 쿼리 슬롯 (설정하지 않으면 nil)
+
+	pauseStatus    string        // 감시할 서버 상태 변수 (비어있으면 감시하지 않음)
+	pauseThreshold int64         // 이 값을 넘으면 일시 정지
+	pauseInterval  time.Duration // 상태 변수 확인 주기
+
+	mu     sync.Mutex
+	paused chan struct{} // 일시 정지 중이면 재개할 때 닫히는 채널 (아니면 nil)
+}
+
+// NewThrottle 설정된 제한으로 Throttle을 만듭니다 (제한이 없으면 nil)
+func NewThrottle(config *BackupConfig) *Throttle {
+	t := &Throttle{
+		pauseInterval: config.PauseCheckInterval,
+	}
+	if config.MaxRowsPerSec > 0 {
+		t.rows = newTokenBucket(int64(config.MaxRowsPerSec))
+	}
+	if config.MaxBytesPerSec > 0 {
+		t.bytes = newTokenBucket(int64(config.MaxBytesPerSec))
+	}
+	if config.MaxQueries > 0 {
+		t.queries = make(chan struct{}, config.MaxQueries)
+	}
+	if config.PauseThreshold > 0 && config.PauseStatus != "" {
+		t.pauseStatus = config.PauseStatus
+		t.pauseThreshold = int64(config.PauseThreshold)
+	}
+	if t.pauseInterval <= 0 {
+		t.pauseInterval = 5 * time.Second
+	}
+
+	if t.rows == nil && t.bytes == nil && t.queries == nil && t.pauseStatus == "" {
+		return nil
+	}
+	return t
+}
+
+// AcquireQuery 데이터 쿼리 슬롯을 얻습니다 (일시 정지 중이면 재개될 때까지 대기)
+// 결과를 모두 읽은 뒤 반환된 함수로 슬롯을 돌려줘야 합니다
+func (t *Throttle) AcquireQuery(ctx context.Context) (func(), error) {
+	if t == nil {
+		return func() {}, nil
+	}
+	if err := t.waitResume(ctx); err != nil {
+		return nil, err
+	}
+	if t.queries == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case t.queries <- struct{}{}:
+		return func() { <-t.queries }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Wait 읽은 행 수와 바이트 수만큼 토큰을 가져갑니다 (일시 정지 중이면 재개될 때까지 대기)
+func (t *Throttle) Wait(ctx context.Context, rows, bytes int64) error {
+	if t == nil {
+		return nil
+	}
+	if err := t.waitResume(ctx); err != nil {
+		return err
+	}
+	if err := t.rows.Wait(ctx, rows); err != nil {
+		return err
+	}
+	return t.bytes.Wait(ctx, bytes)
+}
+
+func (t *Throttle) waitResume(ctx context.Context) error {
+	t.mu.Lock()
+	paused := t.paused
+	t.mu.Unlock()
+	if paused == nil {
+		return nil
+	}
+
+	select {
+	case <-paused:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunLoadGuard ctx가 끝날 때까지 서버 상태 변수를 주기적으로 확인해 임계값을 넘으면 일시 정지합니다
+// 상태를 조회하지 못하면 백업을 막지 않도록 정지하지 않은 상태로 둡니다
+func (t *Throttle) RunLoadGuard(ctx context.Context, db *sql.DB, logger *slog.Logger) {
+	if t == nil || t.pauseStatus == "" {
+		return
+	}
+	defer t.setPaused(false)
+
+	ticker := time.NewTicker(t.pauseInterval)
+	defer ticker.Stop()
+
+	for {
+		value, err := serverStatus(ctx, db, t.pauseStatus)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn("서버 상태 변수 조회 실패", "status", t.pauseStatus, "error", err)
+			if t.setPaused(false) {
+				logger.Info("서버 상태를 확인할 수 없어 백업을 재개합니다", "status", t.pauseStatus)
+			}
+		case value > t.pauseThreshold:
+			if t.setPaused(true) {
+				logger.Warn("서버 부하가 임계값을 넘어 백업을 일시 정지합니다",
+					"status", t.pauseStatus, "value", value, "threshold", t.pauseThreshold)
+			}
+		default:
+			if t.setPaused(false) {
+				logger.Info("서버 부하가 내려가 백업을 재개합니다",
+					"status", t.pauseStatus, "value", value, "threshold", t.pauseThreshold)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setPaused 일시 정지 상태를 바꾸고, 바뀌었으면 true를 반환합니다
+func (t *Throttle) setPaused(paused bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case paused && t.paused == nil:
+		t.paused = make(chan struct{})
+		return true
+	case !paused && t.paused != nil:
+		close(t.paused)
+		t.paused = nil
+		return true
+	}
+	return false
+}
+
+// serverStatus SHOW GLOBAL STATUS로 정수 상태 변수 값을 조회합니다
+func serverStatus(ctx context.Context, db *sql.DB, name string) (int64, error) {
+	var variable, value string
+	query := fmt.Sprintf("SHOW GLOBAL STATUS LIKE '%s'", escapeSQLString(name))
+	if err := db.QueryRowContext(ctx, query).Scan(&variable, &value); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("상태 변수 '%s'이(가) 없습니다", name)
+		}
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// rowSize 처리량 제한에 쓰는 행의 대략적인 크기 (서버에서 받은 값의 바이트 수)
+func rowSize(values []interface{}) int64 {
+	var size int64
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case []byte:
+			size += int64(len(v))
+		case string:
+			size += int64(len(v))
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+// sleepContext d만큼 기다리거나 ctx가 끝나면 바로 반환합니다
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketWait(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		tokens   float64       // 버킷에 남은 토큰
+		idle     time.Duration // 마지막 요청 이후 지난 시간
+		n        int64
+		want     float64 // 요청 후 남은 토큰 (음수면 그만큼 빌려 쓰고 기다림)
+		wantWait bool
+	}{
+		{"within budget", 1000, 0, 300, 700, false},
+		{"refill at rate", 0, 500 * time.Millisecond, 100, 400, false},
+		{"refill capped at burst", 0, 10 * time.Second, 100, 900, false},
+		{"borrow when short", 50, 0, 100, -50, true},
+		{"request larger than one second", 1000, 0, 5000, -4000, true},
+		{"debt from earlier request", -2000, 0, 1, -2001, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(1000)
+			b.tokens = tt.tokens
+			b.last = time.Now().Add(-tt.idle)
+
+			// 취소된 ctx로 호출하면 기다려야 할 때만 오류가 나므로 잠들지 않고 계산 결과를 확인할 수 있음
+			err := b.Wait(cancelled, tt.n)
+			if gotWait := errors.Is(err, context.Canceled); gotWait != tt.wantWait {
+				t.Errorf("waited = %v (err %v), want %v", gotWait, err, tt.wantWait)
+			}
+			// 호출 사이에 흐른 시간만큼의 보충은 허용
+			if math.Abs(b.tokens-tt.want) > 20 {
+				t.Errorf("tokens = %.1f, want about %.1f", b.tokens, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenBucketSleepsForDebt(t *testing.T) {
+	b := newTokenBucket(1000)
+	b.tokens = 0
+
+	start := time.Now()
+	if err := b.Wait(context.Background(), 50); err != nil {
+		t.Fatal(err)
+	}
+	// 50개를 빌렸으므로 초당 1000개 기준 약 50ms
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond || elapsed > time.Second {
+		t.Errorf("waited %v, want about 50ms", elapsed)
+	}
+}
+
+func TestTokenBucketCancelWhileWaiting(t *testing.T) {
+	b := newTokenBucket(10)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	// 1000개는 초당 10개로 100초가 걸리지만 ctx가 끝나면 바로 반환
+	err := b.Wait(ctx, 1000)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait returned after %v", elapsed)
+	}
+}
+
+func TestTokenBucketNoop(t *testing.T) {
+	var nilBucket *tokenBucket
+	if err := nilBucket.Wait(context.Background(), 100); err != nil {
+		t.Errorf("nil bucket: %v", err)
+	}
+	b := newTokenBucket(1)
+	b.tokens = -100
+	if err := b.Wait(context.Background(), 0); err != nil || b.tokens != -100 {
+		t.Errorf("zero request: err %v, tokens %v", err, b.tokens)
+	}
+}
+
+func TestRowSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []interface{}
+		want   int64
+	}{
+		{"empty", nil, 0},
+		{"nulls", []interface{}{nil, nil}, 0},
+		{"bytes and strings", []interface{}{[]byte("abcd"), "한글"}, 4 + 6},
+		{"fixed size values", []interface{}{int64(1), 1.5, time.Now()}, 24},
+		{"mixed", []interface{}{int64(1), nil, []byte{}, "x"}, 9},
+	}
+	for _, tt := range tests {
+		if got := rowSize(tt.values); got != tt.want {
+			t.Errorf("%s: rowSize = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewThrottle(t *testing.T) {
+	if th := NewThrottle(&BackupConfig{}); th != nil {
+		t.Error("NewThrottle without limits != nil")
+	}
+	if th := NewThrottle(&BackupConfig{PauseThreshold: 10}); th != nil {
+		t.Error("pause threshold without a status variable should not enable throttling")
+	}
+	th := NewThrottle(&BackupConfig{MaxRowsPerSec: 100, MaxQueries: 1})
+	if th == nil || th.rows == nil || th.bytes != nil || cap(th.queries) != 1 || th.pauseInterval != 5*time.Second {
+		t.Fatalf("throttle = %+v", th)
+	}
+
+	// 동시 쿼리 슬롯이 하나뿐이면 두 번째 요청은 슬롯이 돌아올 때까지 대기
+	release, err := th.AcquireQuery(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := th.AcquireQuery(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second AcquireQuery err = %v, want deadline exceeded", err)
+	}
+	release()
+	if release, err := th.AcquireQuery(context.Background()); err != nil {
+		t.Errorf("AcquireQuery after release: %v", err)
+	} else {
+		release()
+	}
+}
+
+func TestThrottlePause(t *testing.T) {
+	th := NewThrottle(&BackupConfig{PauseStatus: "Threads_running", PauseThreshold: 10})
+	if !th.setPaused(true) || th.setPaused(true) {
+		t.Error("setPaused(true) should report a change only once")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := th.Wait(ctx, 1, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait while paused err = %v, want deadline exceeded", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- th.Wait(context.Background(), 1, 1) }()
+	th.setPaused(false)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Wait after resume: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after resume")
+	}
+}